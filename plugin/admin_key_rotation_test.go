@@ -5,9 +5,16 @@ package openaisecrets
 
 import (
 	context "context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/stretchr/testify/assert"
@@ -53,8 +60,8 @@ func TestAdminKeyRotation_Manual(t *testing.T) {
 	t.Logf("Config after write: admin_api_key=%q last_rotated_time=%v", cfg.AdminAPIKey, cfg.LastRotatedTime)
 
 	// Spy on rotateAdminAPIKey
-	rotated, err := b.rotateAdminAPIKey(ctx, storage)
-	if !rotated || err != nil {
+	rotated, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
+	if err != nil {
 		t.Logf("rotateAdminAPIKey returned rotated=%v, err=%v", rotated, err)
 		cfg, cfgErr := getConfig(ctx, storage)
 		if cfgErr != nil {
@@ -64,7 +71,7 @@ func TestAdminKeyRotation_Manual(t *testing.T) {
 		}
 	}
 	assert.NoError(t, err)
-	assert.True(t, rotated)
+	assert.NotNil(t, rotated)
 }
 
 func TestAdminKeyRotation_Schedule(t *testing.T) {
@@ -146,9 +153,9 @@ func TestAdminKeyRotation_Automatic(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test that we can trigger rotation directly (simulating automated rotation)
-	rotated, err := b.rotateAdminAPIKey(ctx, storage)
+	rotated, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
 	assert.NoError(t, err)
-	assert.True(t, rotated, "Admin key should be rotated")
+	assert.NotNil(t, rotated, "Admin key should be rotated")
 
 	// Check if the config was updated with a new API key
 	cfg, err := getConfig(ctx, storage)
@@ -156,3 +163,644 @@ func TestAdminKeyRotation_Automatic(t *testing.T) {
 	assert.NotEqual(t, "test-key", cfg.AdminAPIKey, "API key should have been rotated")
 	assert.Contains(t, cfg.AdminAPIKey, "sk-adminkey", "API key should match the mock implementation")
 }
+
+func TestAdminKeyRotation_RotateOnWrite(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	resp, err := b.pathConfigWrite(ctx, &logical.Request{
+		Storage:    storage,
+		MountPoint: TestMountPoint,
+		Path:       TestConfigPath,
+	}, &framework.FieldData{
+		Raw: map[string]interface{}{
+			"admin_api_key":    TestAPIKey,
+			"admin_api_key_id": TestAdminAPIKeyID,
+			"organization_id":  TestOrganizationID,
+			"api_endpoint":     mockServer.URL() + "/v1",
+			"rotate_on_write":  true,
+		},
+		Schema: b.pathAdminConfig()[1].Fields,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	require.False(t, resp.IsError(), "unexpected error: %v", resp.Error())
+	assert.Equal(t, true, resp.Data["rotated"])
+	assert.Empty(t, resp.Warnings)
+
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.NotEqual(t, TestAPIKey, cfg.AdminAPIKey, "bootstrap key must be replaced")
+	assert.NotEqual(t, TestAdminAPIKeyID, cfg.AdminAPIKeyID)
+	assert.Equal(t, cfg.AdminAPIKeyID, resp.Data["admin_api_key_id"])
+	assert.False(t, mockServer.HasAdminAPIKey(TestAdminAPIKeyID), "bootstrap key must be revoked")
+	assert.True(t, mockServer.HasAdminAPIKey(cfg.AdminAPIKeyID))
+}
+
+func TestAdminKeyRotation_RotateOnWriteRevokesBootstrapDuringOverlap(t *testing.T) {
+	for _, revokeFails := range []bool{false, true} {
+		t.Run(fmt.Sprintf("revoke fails %v", revokeFails), func(t *testing.T) {
+			mockServer := NewMockOpenAIServer()
+			defer mockServer.Close()
+			if revokeFails {
+				mockServer.SetFailureMode("revoke_admin_key", 500, "admin keys unavailable")
+			}
+
+			b := getTestBackend(t)
+			storage := &logical.InmemStorage{}
+			ctx := context.Background()
+
+			resp, err := b.pathConfigWrite(ctx, &logical.Request{
+				Storage:    storage,
+				MountPoint: TestMountPoint,
+				Path:       TestConfigPath,
+			}, &framework.FieldData{
+				Raw: map[string]interface{}{
+					"admin_api_key":          TestAPIKey,
+					"admin_api_key_id":       TestAdminAPIKeyID,
+					"organization_id":        TestOrganizationID,
+					"api_endpoint":           mockServer.URL() + "/v1",
+					"request_retry_attempts": 1,
+					"rotation_overlap":       3600,
+					"rotate_on_write":        true,
+				},
+				Schema: b.pathAdminConfig()[1].Fields,
+			})
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+			require.False(t, resp.IsError(), "unexpected error: %v", resp.Error())
+			assert.Equal(t, true, resp.Data["rotated"])
+			assert.NotEmpty(t, resp.Data["admin_api_key_name"], "the write's verification is kept in the response")
+
+			cfg, err := getConfig(ctx, storage)
+			require.NoError(t, err)
+			assert.Equal(t, cfg.AdminAPIKeyID, resp.Data["admin_api_key_id"])
+
+			if revokeFails {
+				require.Len(t, resp.Warnings, 1)
+				assert.Contains(t, resp.Warnings[0], TestAdminAPIKeyID)
+				assert.Contains(t, resp.Warnings[0], "remains valid")
+				require.Len(t, cfg.RetiredAdminKeys, 1, "the revocation is retried in the background")
+				assert.Equal(t, 1, cfg.RetiredAdminKeys[0].Attempts)
+				return
+			}
+			assert.Empty(t, resp.Warnings)
+			assert.Empty(t, cfg.RetiredAdminKeys)
+			assert.False(t, mockServer.HasAdminAPIKey(TestAdminAPIKeyID), "the overlap does not apply to the bootstrap key")
+		})
+	}
+}
+
+// writeMockServerConfig writes the default connection against the mock server
+// with the mock's seeded admin key.
+func writeMockServerConfig(t *testing.T, b *backend, storage logical.Storage, mockServer *MockOpenAIServer, extra map[string]interface{}) {
+	t.Helper()
+	raw := map[string]interface{}{
+		"admin_api_key":    TestAPIKey,
+		"admin_api_key_id": TestAdminAPIKeyID,
+		"organization_id":  TestOrganizationID,
+		"api_endpoint":     mockServer.URL() + "/v1",
+		// Failures injected into the mock server are meant for the rotation
+		// logic, not for request retries.
+		"request_retry_attempts": 1,
+	}
+	for k, v := range extra {
+		raw[k] = v
+	}
+	resp, err := b.pathConfigWrite(context.Background(), &logical.Request{
+		Storage:    storage,
+		MountPoint: TestMountPoint,
+		Path:       TestConfigPath,
+	}, &framework.FieldData{Raw: raw, Schema: b.pathAdminConfig()[1].Fields})
+	require.NoError(t, err)
+	require.False(t, resp != nil && resp.IsError(), "unexpected error: %v", resp)
+}
+
+func TestAdminKeyRotation_OverlapDefersRevocation(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	writeMockServerConfig(t, b, storage, mockServer, map[string]interface{}{"rotation_overlap": 3600})
+
+	rotated, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
+	require.NoError(t, err)
+	require.NotNil(t, rotated)
+
+	// The old key stays live during the overlap window and is recorded.
+	assert.True(t, mockServer.HasAdminAPIKey(TestAdminAPIKeyID))
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	require.Len(t, cfg.RetiredAdminKeys, 1)
+	assert.Equal(t, TestAdminAPIKeyID, cfg.RetiredAdminKeys[0].ID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), cfg.RetiredAdminKeys[0].RevokeAfter, time.Minute)
+
+	resp, err := b.pathConfigRead(ctx, &logical.Request{Storage: storage}, &framework.FieldData{})
+	require.NoError(t, err)
+	retired := resp.Data["retired_admin_keys"].([]map[string]interface{})
+	require.Len(t, retired, 1)
+	assert.Equal(t, TestAdminAPIKeyID, retired[0]["admin_api_key_id"])
+	assert.Equal(t, int64(3600), resp.Data["rotation_overlap"])
+
+	// Before the window ends the periodic function leaves the key alone.
+	require.NoError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+	assert.True(t, mockServer.HasAdminAPIKey(TestAdminAPIKeyID))
+
+	// Once the window has ended the key is revoked and the record removed.
+	cfg.RetiredAdminKeys[0].RevokeAfter = time.Now().Add(-time.Second)
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, cfg))
+	require.NoError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+	assert.False(t, mockServer.HasAdminAPIKey(TestAdminAPIKeyID))
+
+	cfg, err = getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.RetiredAdminKeys)
+}
+
+func TestAdminKeyRotation_FailedRevocationIsRetried(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+
+	// Revocation failure no longer fails a rotation that otherwise succeeded.
+	mockServer.SetFailureMode("revoke_admin_key", 500, "revocation unavailable")
+	rotated, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
+	require.NoError(t, err)
+	require.NotNil(t, rotated)
+	assert.True(t, mockServer.HasAdminAPIKey(TestAdminAPIKeyID))
+
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.NotEqual(t, TestAdminAPIKeyID, cfg.AdminAPIKeyID)
+	require.Len(t, cfg.RetiredAdminKeys, 1)
+	assert.Equal(t, 1, cfg.RetiredAdminKeys[0].Attempts)
+	assert.Contains(t, cfg.RetiredAdminKeys[0].LastError, "revocation unavailable")
+	assert.True(t, cfg.RetiredAdminKeys[0].RevokeAfter.After(time.Now()), "retry must be backed off")
+
+	// The periodic function retries once the backoff has elapsed.
+	mockServer.ClearFailureMode()
+	cfg.RetiredAdminKeys[0].RevokeAfter = time.Now().Add(-time.Second)
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, cfg))
+	require.NoError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+	assert.False(t, mockServer.HasAdminAPIKey(TestAdminAPIKeyID))
+
+	cfg, err = getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.RetiredAdminKeys)
+}
+
+func TestRetiredKeyRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Minute, retiredKeyRetryDelay(1))
+	assert.Equal(t, 2*time.Minute, retiredKeyRetryDelay(2))
+	assert.Equal(t, 8*time.Minute, retiredKeyRetryDelay(4))
+	assert.Equal(t, time.Hour, retiredKeyRetryDelay(20))
+}
+
+// failingConfigStorage fails writes to the default connection's config, to
+// simulate the plugin losing storage between creating and saving a new key.
+type failingConfigStorage struct {
+	logical.Storage
+}
+
+func (s *failingConfigStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	if entry.Key == configPath {
+		return errors.New("storage unavailable")
+	}
+	return s.Storage.Put(ctx, entry)
+}
+
+func TestAdminKeyRotation_WALRollback(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+
+	// Saving the rotated config fails after the new key was created.
+	failing := &failingConfigStorage{Storage: storage}
+	rotated, err := b.rotateAdminAPIKey(ctx, failing, defaultConnectionName)
+	require.Error(t, err)
+	assert.Equal(t, &adminKeyRotation{OldKeyID: TestAdminAPIKeyID}, rotated, "a failed rotation names the key it was to replace")
+	require.Len(t, mockServer.AdminAPIKeyIDs(), 2, "the orphaned key exists until rolled back")
+
+	walIDs, err := framework.ListWAL(ctx, failing)
+	require.NoError(t, err)
+	require.Len(t, walIDs, 1)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.RollbackOperation,
+		Storage:   failing,
+		Data:      map[string]interface{}{"immediate": true},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{TestAdminAPIKeyID}, mockServer.AdminAPIKeyIDs(), "only the configured key survives")
+	walIDs, err = framework.ListWAL(ctx, failing)
+	require.NoError(t, err)
+	assert.Empty(t, walIDs)
+}
+
+func TestAdminKeyRotation_WALRollbackAfterConfigDelete(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+
+	failing := &failingConfigStorage{Storage: storage}
+	_, err := b.rotateAdminAPIKey(ctx, failing, defaultConnectionName)
+	require.Error(t, err)
+	require.Len(t, mockServer.AdminAPIKeyIDs(), 2)
+
+	// Deleting the connection revokes the orphaned key while its admin key
+	// is still known.
+	resp, err := b.pathConfigDelete(ctx, &logical.Request{Storage: storage, Path: TestConfigPath}, &framework.FieldData{})
+	require.NoError(t, err)
+	require.False(t, resp != nil && resp.IsError(), "unexpected error: %v", resp)
+	assert.Equal(t, []string{TestAdminAPIKeyID}, mockServer.AdminAPIKeyIDs())
+	walIDs, err := framework.ListWAL(ctx, storage)
+	require.NoError(t, err)
+	assert.Empty(t, walIDs)
+
+	// An entry whose connection is gone is kept for a later rollback.
+	walID, err := framework.PutWAL(ctx, storage, adminKeyRotationWALKind, &adminKeyRotationWAL{
+		Connection: "deleted",
+		KeyName:    vaultAdminKeyNamePrefix + "orphan",
+	})
+	require.NoError(t, err)
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.RollbackOperation,
+		Storage:   storage,
+		Data:      map[string]interface{}{"immediate": true},
+	})
+	require.NoError(t, err)
+	wal, err := framework.GetWAL(ctx, storage, walID)
+	require.NoError(t, err)
+	assert.NotNil(t, wal, "the WAL entry must not be lost")
+
+	err = b.walRollback(ctx, &logical.Request{Storage: storage}, adminKeyRotationWALKind, wal.Data)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no longer configured")
+}
+
+func TestConfigDelete_RefusedWhileRotationCannotBeRolledBack(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+	_, err := b.rotateAdminAPIKey(ctx, &failingConfigStorage{Storage: storage}, defaultConnectionName)
+	require.Error(t, err)
+
+	mockServer.SetFailureMode("revoke_admin_key", 500, "admin keys unavailable")
+	resp, err := b.pathConfigDelete(ctx, &logical.Request{Storage: storage, Path: TestConfigPath}, &framework.FieldData{})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "incomplete rotation")
+
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.NotNil(t, cfg, "the connection is kept")
+	walIDs, err := framework.ListWAL(ctx, storage)
+	require.NoError(t, err)
+	assert.Len(t, walIDs, 1)
+}
+
+func TestAdminKeyRotation_WALRollbackKeepsCompletedRotation(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+
+	rotated, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
+	require.NoError(t, err)
+	require.NotNil(t, rotated)
+
+	walIDs, err := framework.ListWAL(ctx, storage)
+	require.NoError(t, err)
+	assert.Empty(t, walIDs, "a completed rotation clears its WAL entry")
+
+	// A WAL entry left behind after the config was saved must not revoke
+	// the key now in use.
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	require.NoError(t, b.walRollback(ctx, &logical.Request{Storage: storage}, adminKeyRotationWALKind, map[string]interface{}{
+		"connection": defaultConnectionName,
+		"key_id":     cfg.AdminAPIKeyID,
+	}))
+	assert.True(t, mockServer.HasAdminAPIKey(cfg.AdminAPIKeyID))
+}
+
+func TestAdminKeyRotation_RetryPolicy(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	writeMockServerConfig(t, b, storage, mockServer, map[string]interface{}{
+		"rotation_retry_attempts":   2,
+		"rotation_retry_base_delay": 3600,
+		"rotation_retry_max_delay":  3600,
+	})
+
+	resp, err := b.pathConfigRead(ctx, &logical.Request{Storage: storage}, &framework.FieldData{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Data["rotation_retry_attempts"])
+	assert.Equal(t, int64(3600), resp.Data["rotation_retry_base_delay"])
+
+	// A cancelled rotation returns promptly instead of sleeping out the
+	// hour-long backoff.
+	mockServer.SetFailureMode("create_admin_key", 503, "temporarily unavailable")
+	cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	rotated, err := b.rotateAdminAPIKey(cancelCtx, storage, defaultConnectionName)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, &adminKeyRotation{OldKeyID: TestAdminAPIKeyID}, rotated, "a failed rotation names the key it was to replace")
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, TestAdminAPIKeyID, cfg.AdminAPIKeyID)
+}
+
+// fakeClientFactory returns a ClientFactory that hands out the fake for each
+// admin key, recording the configs it was called with.
+func fakeClientFactory(clients map[string]*mockClient, configs *[]*Config) ClientFactory {
+	return func(config *Config, _ hclog.Logger) (ClientAPI, error) {
+		*configs = append(*configs, config)
+		client, ok := clients[config.AdminAPIKey]
+		if !ok {
+			return nil, fmt.Errorf("no fake client for admin key %q", config.AdminAPIKey)
+		}
+		return client, nil
+	}
+}
+
+func TestAdminKeyRotation_ClientFactory(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, &openaiConfig{
+		AdminAPIKey:    "sk-admin-old",
+		AdminAPIKeyID:  "key-old",
+		OrganizationID: TestOrganizationID,
+		APIEndpoint:    DefaultAPIEndpoint,
+	}))
+
+	var createdName string
+	oldClient := &mockClient{
+		createAdminAPIKeyFn: func(_ context.Context, name string) (string, string, error) {
+			createdName = name
+			return "sk-admin-new", "key-new", nil
+		},
+	}
+	var revoked []string
+	newClient := &mockClient{
+		revokeAdminAPIKeyFn: func(_ context.Context, keyID string) error {
+			revoked = append(revoked, keyID)
+			return nil
+		},
+	}
+	var configs []*Config
+	b.newClient = fakeClientFactory(map[string]*mockClient{
+		"sk-admin-old": oldClient,
+		"sk-admin-new": newClient,
+	}, &configs)
+
+	rotated, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
+	require.NoError(t, err)
+	assert.Equal(t, &adminKeyRotation{OldKeyID: "key-old", NewKeyID: "key-new"}, rotated)
+	assert.True(t, strings.HasPrefix(createdName, vaultAdminKeyNamePrefix))
+
+	// The clients that create and validate the key send each request once;
+	// the rotation retries the steps. The client that replaces the cached one
+	// carries the new key and its ID, retries as configured, and revokes the
+	// old key.
+	require.Len(t, configs, 3)
+	assert.Equal(t, 1, configs[0].RequestRetryAttempts)
+	assert.Equal(t, "key-new", configs[1].AdminAPIKeyID)
+	assert.Equal(t, 1, configs[1].RequestRetryAttempts)
+	assert.Equal(t, "key-new", configs[2].AdminAPIKeyID)
+	assert.Zero(t, configs[2].RequestRetryAttempts)
+	assert.Equal(t, []string{"key-old"}, revoked)
+	assert.Same(t, newClient, b.getClient(defaultConnectionName))
+
+	config, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-admin-new", config.AdminAPIKey)
+	assert.Equal(t, "key-new", config.AdminAPIKeyID)
+	assert.Empty(t, config.RetiredAdminKeys)
+
+	wals, err := framework.ListWAL(ctx, storage)
+	require.NoError(t, err)
+	assert.Empty(t, wals)
+}
+
+func TestAdminKeyRotation_NewKeyFailsValidation(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, &openaiConfig{
+		AdminAPIKey:           "sk-admin-old",
+		AdminAPIKeyID:         "key-old",
+		OrganizationID:        TestOrganizationID,
+		APIEndpoint:           DefaultAPIEndpoint,
+		RotationRetryAttempts: 1,
+	}))
+
+	var configs []*Config
+	b.newClient = fakeClientFactory(map[string]*mockClient{
+		"sk-admin-old": {},
+		"sk-admin-new": {testConnectionFn: func(context.Context) error {
+			return errors.New("invalid_api_key")
+		}},
+	}, &configs)
+
+	rotated, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
+	require.Error(t, err)
+	assert.Equal(t, &adminKeyRotation{OldKeyID: "key-old"}, rotated, "a failed rotation names the key it was to replace")
+	assert.Contains(t, err.Error(), "failed validation")
+
+	config, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-admin-old", config.AdminAPIKey, "the old key must stay in use")
+
+	// The WAL entry is left for the rollback to revoke the unused key.
+	wals, err := framework.ListWAL(ctx, storage)
+	require.NoError(t, err)
+	assert.Len(t, wals, 1)
+}
+
+func TestRevokeRetiredAdminKeys_AlreadyRevoked(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, &openaiConfig{
+		AdminAPIKey:    "sk-admin-current",
+		AdminAPIKeyID:  "key-current",
+		OrganizationID: TestOrganizationID,
+		APIEndpoint:    DefaultAPIEndpoint,
+		RetiredAdminKeys: []retiredAdminKey{
+			{ID: "key-gone", RetiredAt: past, RevokeAfter: past},
+			{ID: "key-failing", RetiredAt: past, RevokeAfter: past},
+		},
+	}))
+
+	var configs []*Config
+	b.newClient = fakeClientFactory(map[string]*mockClient{
+		"sk-admin-current": {revokeAdminAPIKeyFn: func(_ context.Context, keyID string) error {
+			if keyID == "key-gone" {
+				return &APIError{StatusCode: 404, Message: "No such admin API key"}
+			}
+			return &APIError{StatusCode: 500}
+		}},
+	}, &configs)
+
+	require.NoError(t, b.revokeRetiredAdminKeys(ctx, storage, defaultConnectionName))
+
+	config, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	require.Len(t, config.RetiredAdminKeys, 1, "a key that no longer exists is not retried")
+	assert.Equal(t, "key-failing", config.RetiredAdminKeys[0].ID)
+	assert.Equal(t, 1, config.RetiredAdminKeys[0].Attempts)
+}
+
+func TestAdminKeyRotation_RetriedCreateRevokesDuplicates(t *testing.T) {
+	for _, revokeFails := range []bool{false, true} {
+		t.Run(fmt.Sprintf("revoke fails %v", revokeFails), func(t *testing.T) {
+			b := getTestBackend(t)
+			storage := &logical.InmemStorage{}
+			ctx := context.Background()
+			require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, &openaiConfig{
+				AdminAPIKey:            "sk-admin-old",
+				AdminAPIKeyID:          "key-old",
+				OrganizationID:         TestOrganizationID,
+				APIEndpoint:            DefaultAPIEndpoint,
+				RotationOverlap:        time.Hour,
+				RotationRetryAttempts:  2,
+				RotationRetryBaseDelay: time.Millisecond,
+			}))
+
+			// The first create reaches OpenAI but its response is lost, so the
+			// retry creates a second key with the same name.
+			var createdName string
+			calls := 0
+			oldClient := &mockClient{
+				createAdminAPIKeyFn: func(_ context.Context, name string) (string, string, error) {
+					createdName = name
+					calls++
+					if calls == 1 {
+						return "", "", errors.New("connection reset by peer")
+					}
+					return "sk-admin-new", "key-new", nil
+				},
+			}
+			var revoked []string
+			newClient := &mockClient{
+				listAdminAPIKeysFn: func(context.Context) ([]*AdminAPIKey, error) {
+					return []*AdminAPIKey{
+						{ID: "key-old", Name: "old"},
+						{ID: "key-lost", Name: createdName},
+						{ID: "key-new", Name: createdName},
+					}, nil
+				},
+				revokeAdminAPIKeyFn: func(_ context.Context, keyID string) error {
+					if revokeFails {
+						return errors.New("temporarily unavailable")
+					}
+					revoked = append(revoked, keyID)
+					return nil
+				},
+			}
+			var configs []*Config
+			b.newClient = fakeClientFactory(map[string]*mockClient{
+				"sk-admin-old": oldClient,
+				"sk-admin-new": newClient,
+			}, &configs)
+
+			rotated, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
+			require.NoError(t, err)
+			assert.NotNil(t, rotated)
+			assert.Equal(t, 2, calls)
+
+			config, err := getConfig(ctx, storage)
+			require.NoError(t, err)
+			assert.Equal(t, "key-new", config.AdminAPIKeyID)
+
+			wals, err := framework.ListWAL(ctx, storage)
+			require.NoError(t, err)
+			if revokeFails {
+				assert.Empty(t, revoked)
+				assert.Len(t, wals, 1, "the WAL entry is kept for the rollback to revoke the duplicate")
+				return
+			}
+			assert.Equal(t, []string{"key-lost"}, revoked)
+			assert.Empty(t, wals)
+		})
+	}
+}
+
+func TestAdminKeyRotation_RetriesCreateOncePerAttempt(t *testing.T) {
+	var posts, lists int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			atomic.AddInt32(&posts, 1)
+		case http.MethodGet:
+			atomic.AddInt32(&lists, 1)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, &openaiConfig{
+		AdminAPIKey:            "sk-admin-old",
+		AdminAPIKeyID:          "key-old",
+		OrganizationID:         TestOrganizationID,
+		APIEndpoint:            server.URL,
+		RotationRetryAttempts:  3,
+		RotationRetryBaseDelay: time.Millisecond,
+		RequestRetryAttempts:   3,
+		RequestRetryBaseDelay:  time.Millisecond,
+	}))
+
+	_, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&posts), "request retries must not multiply rotation attempts")
+	assert.Zero(t, atomic.LoadInt32(&lists), "rejected creates leave no keys to clean up")
+}
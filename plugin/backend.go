@@ -9,15 +9,21 @@ package openaisecrets
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // ReportedVersion is the plugin's self-reported semantic version. Vault uses it
@@ -63,6 +69,35 @@ type ClientAPI interface {
 	GetServiceAccount(ctx context.Context, serviceAccountID, projectID string) (*ServiceAccount, error)
 	ValidateProject(ctx context.Context, projectID string) error
 	GetProject(ctx context.Context, projectID string) (*ProjectInfo, error)
+
+	CreateAdminAPIKey(ctx context.Context, name string) (string, string, error)
+	RevokeAdminAPIKey(ctx context.Context, keyID string) error
+	ListAdminAPIKeys(ctx context.Context) ([]*AdminAPIKey, error)
+	GetAdminAPIKey(ctx context.Context, keyID string) (*AdminAPIKey, error)
+	DiscoverAdminAPIKeyID(ctx context.Context) (string, error)
+	TestConnection(ctx context.Context) error
+}
+
+// ClientFactory builds a client from a connection's client configuration.
+// The backend creates every client through its factory, so tests can
+// substitute fakes.
+type ClientFactory func(config *Config, logger hclog.Logger) (ClientAPI, error)
+
+// newOpenAIClient returns the default ClientFactory. Its clients take their
+// HTTP transport, admission control and circuit breaker from the backend's
+// pools, and trace their API calls with tracerProvider.
+func newOpenAIClient(transports *transportPool, admissions *admissionPool, breakers *breakerPool, tracerProvider trace.TracerProvider) ClientFactory {
+	return func(config *Config, logger hclog.Logger) (ClientAPI, error) {
+		client := NewClient(config.AdminAPIKey, logger)
+		client.transports = transports
+		client.admissions = admissions
+		client.breakers = breakers
+		client.tracer = tracerProvider.Tracer(tracerName)
+		if err := client.SetConfig(config); err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
 }
 
 func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
@@ -72,12 +107,25 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 		return nil, err
 	}
 
+	// Tracing is optional; a misconfigured exporter must not keep the mount
+	// from working.
+	tracerProvider, shutdown, tracingErr := newTracerProviderFromEnv(ctx, os.Getenv)
+
 	// Create a new OpenAI client with the logger from the backend config
 	openaiClient := NewClient("", conf.Logger)
+	openaiClient.tracer = tracerProvider.Tracer(tracerName)
 	b := Backend(openaiClient)
+	b.setTracerProvider(tracerProvider)
+	b.shutdownTracing = shutdown
 	if err := b.Setup(ctx, conf); err != nil {
+		if shutdown != nil {
+			_ = shutdown(ctx)
+		}
 		return nil, err
 	}
+	if tracingErr != nil {
+		b.Logger().Warn("Tracing is disabled", "error", tracingErr)
+	}
 
 	return b, nil
 }
@@ -92,9 +140,18 @@ func Backend(client ClientAPI) *backend {
 	}
 
 	b := &backend{
-		client:    client,
-		roleLocks: locksutil.CreateLocks(),
-		logger:    logger,
+		clients:     make(map[string]ClientAPI),
+		roleLocks:   locksutil.CreateLocks(),
+		configLocks: locksutil.CreateLocks(),
+		logger:      logger,
+
+		transports: newTransportPool(),
+		admissions: newAdmissionPool(),
+		breakers:   newBreakerPool(),
+	}
+	b.setTracerProvider(noop.NewTracerProvider())
+	if client != nil {
+		b.clients[defaultConnectionName] = client
 	}
 
 	b.Backend = &framework.Backend{
@@ -105,15 +162,22 @@ func Backend(client ClientAPI) *backend {
 			},
 			SealWrapStorage: []string{
 				configPath,
+				configPath + "/",
 				// Add any other sensitive storage paths here
 			},
 		},
 		Paths: framework.PathAppend(
 			b.pathAdminConfig(),
+			b.pathRotationStatus(),
+			b.pathHealth(),
+			b.pathConnections(),
 			b.pathDynamicSvcAccount(),
 			b.pathDynamicCredsCreate(),
+			b.pathAdminKeys(),
 		),
 		InitializeFunc: b.initialize,
+		PeriodicFunc:   b.periodicFunc,
+		WALRollback:    b.walRollback,
 		Secrets: []*framework.Secret{
 			dynamicSecretCreds(b),
 		},
@@ -134,19 +198,36 @@ func (b *backend) Setup(ctx context.Context, conf *logical.BackendConfig) error
 
 	// Update the logger from the config if provided
 	if conf.Logger != nil {
-		// Update both the backend logger and the client logger if possible
+		// Update both the backend logger and the client loggers if possible
 		b.logger = conf.Logger
-		if c, ok := b.client.(*Client); ok && c != nil {
-			c.logger = conf.Logger
+		b.Lock()
+		for _, client := range b.clients {
+			if c, ok := client.(*Client); ok && c != nil {
+				c.logger = conf.Logger
+			}
 		}
+		b.Unlock()
 	}
 	return nil
 }
 
+// HandleRequest handles req with the framework backend, passing the Vault
+// request ID to the handlers' context so OpenAI API calls can be correlated
+// with it.
+func (b *backend) HandleRequest(ctx context.Context, req *logical.Request) (*logical.Response, error) {
+	return b.Backend.HandleRequest(withVaultRequestID(ctx, req.ID), req)
+}
+
 func (b *backend) initialize(ctx context.Context, initRequest *logical.InitializationRequest) error {
 	// Store the storage view for later use with cleanup manager
 	b.storageView = initRequest.Storage
 
+	// Entries are upgraded on read as well, so a failed migration is retried
+	// at the next initialization without blocking the mount.
+	if err := b.migrateStorage(ctx, initRequest.Storage); err != nil {
+		b.Logger().Error("Failed to migrate stored entries to the current schema", "error", err)
+	}
+
 	// Load configuration from storage
 	config, err := getConfig(ctx, initRequest.Storage)
 	if err != nil {
@@ -155,28 +236,79 @@ func (b *backend) initialize(ctx context.Context, initRequest *logical.Initializ
 
 	// Initialize the client if config exists
 	if config != nil {
-		client, err := b.configureClientFromStorage(ctx, initRequest.Storage)
+		client, err := b.configureClientFromStorage(ctx, initRequest.Storage, defaultConnectionName)
 		if err != nil {
 			return err
 		}
-		b.Lock()
-		b.client = client
-		b.Unlock()
+		b.setClient(defaultConnectionName, client)
+	}
+
+	// Initialize a client for each named connection
+	names, err := initRequest.Storage.List(ctx, configPath+"/")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		client, err := b.configureClientFromStorage(ctx, initRequest.Storage, name)
+		if err != nil {
+			return err
+		}
+		b.setClient(name, client)
 	}
 
 	return nil
 }
 
-func (b *backend) clean(_ context.Context) {
-	// Cleanup any resources
+// periodicFunc revokes retired admin keys whose overlap window has ended.
+// Vault calls it roughly once a minute on the active node.
+func (b *backend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	// Retired keys are tracked in replicated storage.
+	if !b.canWriteReplicatedStorage() {
+		return nil
+	}
+
+	names, err := req.Storage.List(ctx, configPath+"/")
+	if err != nil {
+		return err
+	}
+	names = append([]string{defaultConnectionName}, names...)
+
+	var errs error
+	for _, name := range names {
+		if err := b.revokeRetiredAdminKeys(ctx, req.Storage, name); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("connection %q: %w", name, err))
+		}
+	}
+	return errs
+}
+
+// canWriteReplicatedStorage reports whether this node may write the mount's
+// replicated storage: only the active node of the primary cluster, or any
+// active node for a local mount.
+func (b *backend) canWriteReplicatedStorage() bool {
+	state := b.System().ReplicationState()
+	return !state.HasState(consts.ReplicationPerformanceStandby) &&
+		(b.System().LocalMount() || !state.HasState(consts.ReplicationPerformanceSecondary))
+}
+
+func (b *backend) clean(ctx context.Context) {
+	b.transports.close()
+
+	if b.shutdownTracing != nil {
+		if err := b.shutdownTracing(ctx); err != nil {
+			b.Logger().Warn("Failed to flush traces", "error", err)
+		}
+	}
 }
 
 type backend struct {
 	*framework.Backend
 	sync.RWMutex
 
-	// client is the OpenAI API client used to interact with the OpenAI API
-	client ClientAPI
+	// clients caches one OpenAI API client per connection, keyed by
+	// connection name. The connection stored at "config" is keyed by
+	// defaultConnectionName.
+	clients map[string]ClientAPI
 
 	// logger stores the plugin's logger
 	logger hclog.Logger
@@ -187,6 +319,41 @@ type backend struct {
 	roleLocks []*locksutil.LockEntry
 
 	storageView logical.Storage
+
+	// configLocks serialize every mutation of a connection's config entry:
+	// config writes and deletes, admin key rotation, and the revocation of
+	// retired admin keys. Use configLock to pick the lock for a connection.
+	configLocks []*locksutil.LockEntry
+
+	// newClient builds the OpenAI clients the backend uses. The default
+	// factory shares the HTTP transport in transports, the admission control
+	// in admissions and the circuit breaker in breakers between the clients
+	// of each connection.
+	newClient  ClientFactory
+	transports *transportPool
+	admissions *admissionPool
+	breakers   *breakerPool
+
+	// tracerProvider creates the spans of backend operations and of the API
+	// calls of the clients built by the default factory. shutdownTracing
+	// flushes and stops the provider; it is nil when tracing is disabled.
+	tracerProvider  trace.TracerProvider
+	shutdownTracing func(context.Context) error
+}
+
+// setTracerProvider traces backend operations, and the API calls of the
+// clients the backend builds from then on, with provider.
+func (b *backend) setTracerProvider(provider trace.TracerProvider) {
+	b.tracerProvider = provider
+	b.newClient = newOpenAIClient(b.transports, b.admissions, b.breakers, provider)
+}
+
+// configLock returns the lock guarding the named connection's config entry.
+func (b *backend) configLock(name string) *locksutil.LockEntry {
+	if name == "" {
+		name = defaultConnectionName
+	}
+	return locksutil.LockForKey(b.configLocks, name)
 }
 
 // Logger returns the backend's logger
@@ -207,22 +374,47 @@ The OpenAI secrets engine creates dynamic API keys for OpenAI:
 The OpenAI secrets engine requires Admin API keys.
 
 After mounting this secrets engine, configure it using the "openai/config" path.
+Additional organizations can be configured as named connections under
+"openai/config/<name>" and referenced by roles.
 `
 
 // rotateRootCredential implements the RotateCredential interface for Vault's rotation framework
 func (b *backend) rotateRootCredential(ctx context.Context, req *logical.Request) error {
-	b.Logger().Info("Root credential rotation triggered by Vault's rotation framework")
+	b.Logger().Info("Root credential rotation triggered by Vault's rotation framework", "path", req.Path)
 
-	// Call the existing rotation implementation
-	rotated, err := b.rotateAdminAPIKey(ctx, req.Storage)
-	if err != nil {
-		return err
+	// Each connection registers its rotation job under its own config path.
+	_, err := b.rotateConnectionCredential(ctx, req.Storage, connectionNameFromPath(req.Path), rotationTriggerScheduled)
+	return err
+}
+
+// rotateConnectionCredential rotates the admin API key of the named connection
+// and records the outcome in the connection's rotation status. It returns the
+// key IDs of a successful rotation.
+func (b *backend) rotateConnectionCredential(ctx context.Context, storage logical.Storage, name, trigger string) (*adminKeyRotation, error) {
+	outcome := rotationOutcome{Time: time.Now(), Trigger: trigger}
+
+	// The key IDs come from the rotation itself, which reads and writes them
+	// under the config lock, so a concurrent rotation cannot be recorded in
+	// this outcome.
+	rotation, err := b.rotateAdminAPIKey(ctx, storage, name)
+	if err == nil && rotation == nil {
+		err = fmt.Errorf("admin API key rotation failed: no API key configured")
 	}
 
-	if !rotated {
-		return fmt.Errorf("admin API key rotation failed: no API key configured")
+	if rotation != nil {
+		outcome.OldAdminAPIKeyID = rotation.OldKeyID
 	}
+	if err != nil {
+		outcome.Error = err.Error()
+	} else {
+		outcome.Success = true
+		outcome.NewAdminAPIKeyID = rotation.NewKeyID
+		b.Logger().Info("Root credential rotation completed successfully", "connection", name)
+	}
+	b.recordRotationOutcome(ctx, storage, name, outcome)
 
-	b.Logger().Info("Root credential rotation completed successfully")
-	return nil
+	if err != nil {
+		return nil, err
+	}
+	return rotation, nil
 }
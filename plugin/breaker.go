@@ -0,0 +1,236 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+const (
+	// DefaultBreakerFailureThreshold is the default number of consecutive
+	// failed requests that opens the circuit breaker.
+	DefaultBreakerFailureThreshold = 5
+
+	// DefaultBreakerCooldown is the default time the circuit breaker stays
+	// open before it lets a probe request through.
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// Circuit breaker states, as reported by the health endpoint.
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+// ErrOpenAIUnavailable is returned without contacting OpenAI while the
+// circuit breaker is open. It wraps consts.ErrOverloaded so Vault answers
+// with 503, which clients retry.
+var ErrOpenAIUnavailable = fmt.Errorf("OpenAI unavailable: %w", consts.ErrOverloaded)
+
+// circuitBreaker stops a client from sending requests to an OpenAI API that
+// keeps failing, so callers fail fast instead of each waiting for a timeout.
+// It opens after threshold consecutive failures. Once the cooldown has
+// passed it is half-open: a single probe request is let through, and its
+// outcome closes the breaker or opens it for another cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openedAt  time.Time
+	lastError string
+	probing   bool
+
+	// now is overridden in tests.
+	now func() time.Time
+}
+
+// breakerStatus is a snapshot of a circuit breaker's state.
+type breakerStatus struct {
+	State               string
+	ConsecutiveFailures int
+	OpenedAt            time.Time
+	RetryAt             time.Time
+	LastError           string
+}
+
+// newCircuitBreaker returns a closed circuit breaker, using the defaults for
+// zero values.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold == 0 {
+		threshold = DefaultBreakerFailureThreshold
+	}
+	if cooldown == 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     breakerClosed,
+		now:       time.Now,
+	}
+}
+
+// breakerPool keeps one circuit breaker for each connection of a mount, so
+// failures seen by any of a connection's clients open the breaker for all of
+// them, and the breaker's state survives client rebuilds. Settings changes
+// apply to the existing breaker without resetting its state.
+type breakerPool struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+// newBreakerPool returns an empty breaker pool.
+func newBreakerPool() *breakerPool {
+	return &breakerPool{breakers: make(map[string]*circuitBreaker)}
+}
+
+// get returns the circuit breaker of the connection named in config with the
+// settings in config, building it on first use. Clients that belong to no
+// connection get a breaker of their own.
+func (p *breakerPool) get(config *Config) *circuitBreaker {
+	configured := newCircuitBreaker(config.BreakerFailureThreshold, config.BreakerCooldown)
+	if config.Connection == "" {
+		return configured
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cb, ok := p.breakers[config.Connection]
+	if !ok {
+		p.breakers[config.Connection] = configured
+		return configured
+	}
+	cb.mu.Lock()
+	cb.threshold = configured.threshold
+	cb.cooldown = configured.cooldown
+	cb.mu.Unlock()
+	return cb
+}
+
+// status returns the state of the named connection's circuit breaker. A
+// connection without one has not sent any requests, so its breaker is
+// reported closed.
+func (p *breakerPool) status(connection string) breakerStatus {
+	p.mu.Lock()
+	cb, ok := p.breakers[connection]
+	p.mu.Unlock()
+	if !ok {
+		return breakerStatus{State: breakerClosed}
+	}
+	return cb.status()
+}
+
+// remove drops the named connection's circuit breaker, for when the
+// connection is deleted.
+func (p *breakerPool) remove(connection string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.breakers, connection)
+}
+
+// validateBreakerSettings checks the configured circuit breaker settings.
+func validateBreakerSettings(threshold int, cooldown time.Duration) error {
+	if threshold < 0 || cooldown < 0 {
+		return fmt.Errorf("breaker_failure_threshold and breaker_cooldown must not be negative")
+	}
+	return nil
+}
+
+// allow reports whether a request may be sent. While the breaker is open it
+// returns an error wrapping ErrOpenAIUnavailable; once the cooldown has
+// passed it lets a single probe through.
+func (cb *circuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case breakerClosed:
+		return nil
+	case breakerOpen:
+		if cb.now().Before(cb.openedAt.Add(cb.cooldown)) {
+			return cb.unavailableLocked()
+		}
+		cb.state = breakerHalfOpen
+	}
+
+	// Half-open: only one probe at a time.
+	if cb.probing {
+		return cb.unavailableLocked()
+	}
+	cb.probing = true
+	return nil
+}
+
+// unavailableLocked returns the error for a request rejected by the open
+// breaker. The caller must hold cb.mu.
+func (cb *circuitBreaker) unavailableLocked() error {
+	return fmt.Errorf("%w: %d consecutive requests failed, retrying after %s (last error: %s)",
+		ErrOpenAIUnavailable, cb.failures, cb.openedAt.Add(cb.cooldown).Format(time.RFC3339), cb.lastError)
+}
+
+// abandon ends a probe let through by allow that was never sent.
+func (cb *circuitBreaker) abandon() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+}
+
+// record updates the breaker with the outcome of an admitted request.
+// Connection errors and 5xx responses are failures; any other response shows
+// that OpenAI is reachable. Requests abandoned by the caller are ignored.
+func (cb *circuitBreaker) record(ctx context.Context, statusCode int, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+
+	if ctx.Err() != nil {
+		// A probe abandoned by its caller proves nothing; let the next
+		// request probe again.
+		return
+	}
+
+	if !shouldFailover(ctx, statusCode, err) {
+		cb.state = breakerClosed
+		cb.failures = 0
+		cb.lastError = ""
+		return
+	}
+
+	cb.failures++
+	cb.lastError = err.Error()
+	if cb.state == breakerHalfOpen || cb.failures >= cb.threshold {
+		cb.state = breakerOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// status returns a snapshot of the breaker's state.
+func (cb *circuitBreaker) status() breakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	status := breakerStatus{
+		State:               cb.state,
+		ConsecutiveFailures: cb.failures,
+		LastError:           cb.lastError,
+	}
+	if cb.state != breakerClosed {
+		status.OpenedAt = cb.openedAt
+		status.RetryAt = cb.openedAt.Add(cb.cooldown)
+		// The next request will probe.
+		if !cb.now().Before(status.RetryAt) {
+			status.State = breakerHalfOpen
+		}
+	}
+	return status
+}
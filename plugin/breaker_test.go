@@ -0,0 +1,197 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cb := newCircuitBreaker(3, time.Minute)
+	cb.now = func() time.Time { return now }
+	ctx := context.Background()
+	outage := errors.New("error making request: connection refused")
+
+	// Answers from OpenAI, including client errors, keep the breaker closed.
+	for range 2 {
+		require.NoError(t, cb.allow())
+		cb.record(ctx, 0, outage)
+	}
+	require.NoError(t, cb.allow())
+	cb.record(ctx, http.StatusNotFound, &APIError{StatusCode: http.StatusNotFound})
+	assert.Equal(t, 0, cb.status().ConsecutiveFailures)
+
+	// Consecutive failures open it.
+	for range 3 {
+		require.NoError(t, cb.allow())
+		cb.record(ctx, http.StatusBadGateway, &APIError{StatusCode: http.StatusBadGateway})
+	}
+	status := cb.status()
+	assert.Equal(t, breakerOpen, status.State)
+	assert.Equal(t, 3, status.ConsecutiveFailures)
+	assert.Equal(t, now.Add(time.Minute), status.RetryAt)
+
+	err := cb.allow()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrOpenAIUnavailable))
+	assert.True(t, errors.Is(err, consts.ErrOverloaded))
+	assert.Contains(t, err.Error(), "OpenAI unavailable")
+	assert.Contains(t, err.Error(), "502")
+
+	// After the cooldown a single probe is let through; a failed probe
+	// opens the breaker again.
+	now = now.Add(time.Minute)
+	assert.Equal(t, breakerHalfOpen, cb.status().State)
+	require.NoError(t, cb.allow())
+	require.Error(t, cb.allow(), "only one probe at a time")
+	cb.record(ctx, 0, outage)
+	assert.Equal(t, breakerOpen, cb.status().State)
+	require.Error(t, cb.allow())
+
+	// A probe that is never sent lets the next request probe.
+	now = now.Add(time.Minute)
+	require.NoError(t, cb.allow())
+	cb.abandon()
+	require.NoError(t, cb.allow())
+
+	// A successful probe closes the breaker.
+	cb.record(ctx, http.StatusOK, nil)
+	status = cb.status()
+	assert.Equal(t, breakerClosed, status.State)
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.Empty(t, status.LastError)
+	require.NoError(t, cb.allow())
+}
+
+func TestCircuitBreaker_IgnoresCancelledRequests(t *testing.T) {
+	cb := newCircuitBreaker(1, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, cb.allow())
+	cb.record(ctx, 0, context.Canceled)
+	assert.Equal(t, breakerClosed, cb.status().State)
+}
+
+func TestValidateBreakerSettings(t *testing.T) {
+	require.NoError(t, validateBreakerSettings(0, 0))
+	require.Error(t, validateBreakerSettings(-1, 0))
+	require.Error(t, validateBreakerSettings(0, -time.Second))
+}
+
+func TestClient_CircuitBreakerFailsFast(t *testing.T) {
+	var hits int32
+	server := endpointTestServer(t, http.StatusServiceUnavailable, &hits)
+
+	client := NewClient(TestAPIKey, hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:             TestAPIKey,
+		OrganizationID:          TestOrganizationID,
+		APIEndpoint:             server.URL,
+		RequestRetryAttempts:    1,
+		BreakerFailureThreshold: 2,
+		BreakerCooldown:         time.Hour,
+	}))
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := client.ListAdminAPIKeys(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+	}
+
+	_, err := client.ListAdminAPIKeys(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrOpenAIUnavailable))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits), "an open breaker must not contact OpenAI")
+	assert.Equal(t, breakerOpen, client.breakerStatus().State)
+}
+
+func TestClient_CircuitBreakerStopsRetries(t *testing.T) {
+	var hits int32
+	server := endpointTestServer(t, http.StatusInternalServerError, &hits)
+
+	client := NewClient(TestAPIKey, hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:             TestAPIKey,
+		OrganizationID:          TestOrganizationID,
+		APIEndpoint:             server.URL,
+		RequestRetryAttempts:    5,
+		BreakerFailureThreshold: 2,
+	}))
+	client.sleep = func(context.Context, time.Duration) error { return nil }
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrOpenAIUnavailable))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestHealth_ReportsCircuitBreaker(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+
+	resp := readHealth(t, b, storage, "config/health")
+	require.NotNil(t, resp)
+	breaker := resp.Data["circuit_breaker"].(map[string]interface{})
+	assert.Equal(t, breakerClosed, breaker["state"])
+	assert.NotContains(t, breaker, "retry_at")
+
+	// Failures seen by a short-lived client of the connection, such as one
+	// built for rotation, open the breaker of the cached client too.
+	other, err := b.configureClientFromStorage(context.Background(), storage, defaultConnectionName)
+	require.NoError(t, err)
+	for range DefaultBreakerFailureThreshold {
+		other.(*Client).breaker.record(context.Background(), http.StatusBadGateway, &APIError{StatusCode: http.StatusBadGateway})
+	}
+	assert.Equal(t, breakerOpen, b.getClient(defaultConnectionName).(*Client).breakerStatus().State)
+
+	// The health check itself still reaches OpenAI.
+	resp = readHealth(t, b, storage, "config/health")
+	require.NotNil(t, resp)
+	assert.Equal(t, healthStatusHealthy, resp.Data["status"])
+	breaker = resp.Data["circuit_breaker"].(map[string]interface{})
+	assert.Equal(t, breakerOpen, breaker["state"])
+	assert.Equal(t, DefaultBreakerFailureThreshold, breaker["consecutive_failures"])
+	assert.Contains(t, breaker["last_error"], "502")
+	assert.NotEmpty(t, breaker["retry_at"])
+}
+
+func TestBreakerPool_OnePerConnection(t *testing.T) {
+	pool := newBreakerPool()
+	assert.Equal(t, breakerClosed, pool.status("default").State)
+
+	cb := pool.get(&Config{Connection: "default", BreakerFailureThreshold: 1})
+	assert.NotSame(t, cb, pool.get(&Config{Connection: "other"}))
+	assert.NotSame(t, cb, pool.get(&Config{}), "clients without a connection are not pooled")
+
+	cb.record(context.Background(), http.StatusBadGateway, &APIError{StatusCode: http.StatusBadGateway})
+	assert.Equal(t, breakerOpen, pool.status("default").State)
+
+	// New settings apply to the open breaker without closing it.
+	same := pool.get(&Config{Connection: "default", BreakerFailureThreshold: 3, BreakerCooldown: time.Hour})
+	assert.Same(t, cb, same)
+	assert.Equal(t, 3, same.threshold)
+	assert.Equal(t, time.Hour, same.cooldown)
+	assert.Equal(t, breakerOpen, pool.status("default").State)
+
+	pool.remove("default")
+	assert.Equal(t, breakerClosed, pool.status("default").State)
+}
@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 const (
@@ -36,16 +38,57 @@ type Client struct {
 	adminAPIKeyID  string
 	organizationID string
 	logger         hclog.Logger
+
+	// endpoints holds apiEndpoint followed by any failover endpoints.
+	endpoints *endpointPool
+
+	// headers are the operator's custom headers, sent with every request.
+	headers    http.Header
+	openAIBeta string
+
+	// retryPolicy governs retries of failed requests; sleep waits between
+	// them and is overridden in tests.
+	retryPolicy retryPolicy
+	sleep       func(ctx context.Context, d time.Duration) error
+
+	// admission rate limits requests and caps those in flight.
+	admission *admissionControl
+
+	// breaker fails requests fast while OpenAI is unavailable.
+	breaker *circuitBreaker
+
+	// transports, when set, provides the HTTP transport shared with the
+	// other clients of the connection.
+	transports *transportPool
+
+	// admissions, when set, provides the admission control shared with the
+	// other clients of the connection.
+	admissions *admissionPool
+
+	// breakers, when set, provides the circuit breaker shared with the other
+	// clients of the connection.
+	breakers *breakerPool
+
+	// tracer traces API calls. It is a no-op unless the client factory sets
+	// the backend's tracer.
+	tracer trace.Tracer
 }
 
 // NewClient creates a new OpenAI client
 func NewClient(adminAPIKey string, logger hclog.Logger) *Client {
 	return &Client{
-		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		httpClient:     &http.Client{Timeout: DefaultRequestTimeout},
 		apiEndpoint:    DefaultAPIEndpoint,
 		adminAPIKey:    adminAPIKey,
 		organizationID: "", // Will be set through SetConfig
 		logger:         logger,
+		endpoints:      newEndpointPool([]string{DefaultAPIEndpoint}, 0),
+		openAIBeta:     DefaultOpenAIBeta,
+		retryPolicy:    defaultRequestRetryPolicy,
+		sleep:          sleepContext,
+		admission:      newAdmissionControl(0, 0, 0),
+		breaker:        newCircuitBreaker(0, 0),
+		tracer:         noop.NewTracerProvider().Tracer(tracerName),
 	}
 }
 
@@ -91,6 +134,48 @@ type Config struct {
 	AdminAPIKeyID  string `json:"admin_api_key_id,omitempty"`
 	APIEndpoint    string `json:"api_endpoint"`
 	OrganizationID string `json:"organization_id"`
+
+	// Connection names the connection the client is built for. Clients of
+	// the same connection share its HTTP transport, admission control and
+	// circuit breaker.
+	Connection string `json:"-"`
+
+	// ownBreaker gives the client a circuit breaker of its own, for checks
+	// that must reach OpenAI while the connection's breaker is open.
+	ownBreaker bool
+
+	// APIEndpoints, when set, lists APIEndpoint followed by the endpoints to
+	// fail over to, in order of preference.
+	APIEndpoints     []string      `json:"api_endpoints,omitempty"`
+	EndpointCooldown time.Duration `json:"endpoint_cooldown,omitempty"`
+
+	// Extra headers sent with every request, and the OpenAI-Beta header
+	// value. An empty OpenAIBeta uses DefaultOpenAIBeta.
+	CustomHeaders          map[string]string `json:"custom_headers,omitempty"`
+	SensitiveCustomHeaders map[string]string `json:"sensitive_custom_headers,omitempty"`
+	OpenAIBeta             string            `json:"openai_beta,omitempty"`
+
+	// Retry policy for failed requests. Zero values use the defaults.
+	RequestRetryAttempts  int           `json:"request_retry_attempts,omitempty"`
+	RequestRetryBaseDelay time.Duration `json:"request_retry_base_delay,omitempty"`
+	RequestRetryMaxDelay  time.Duration `json:"request_retry_max_delay,omitempty"`
+
+	// Client-side rate limiting. Zero values use the defaults.
+	RateLimit         float64 `json:"rate_limit,omitempty"`
+	MaxInFlight       int     `json:"max_in_flight,omitempty"`
+	MaxQueuedRequests int     `json:"max_queued_requests,omitempty"`
+
+	// Circuit breaker. Zero values use the defaults.
+	BreakerFailureThreshold int           `json:"breaker_failure_threshold,omitempty"`
+	BreakerCooldown         time.Duration `json:"breaker_cooldown,omitempty"`
+
+	// Egress settings used to build the HTTP client
+	CACertificate     string        `json:"ca_certificate,omitempty"`
+	ClientCertificate string        `json:"client_certificate,omitempty"`
+	ClientKey         string        `json:"client_key,omitempty"`
+	ProxyURL          string        `json:"proxy_url,omitempty"`
+	ConnectTimeout    time.Duration `json:"connect_timeout,omitempty"`
+	RequestTimeout    time.Duration `json:"request_timeout,omitempty"`
 }
 
 // ServiceAccount represents an OpenAI project service account
@@ -165,6 +250,17 @@ func (ak *APIKey) GetExpiresAt() *time.Time {
 	return ak.ExpiresAt.TimePtr()
 }
 
+// AdminAPIKey represents an organization admin API key. Value is only
+// returned when the key is created.
+type AdminAPIKey struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	RedactedValue string    `json:"redacted_value"`
+	Value         string    `json:"value,omitempty"`
+	CreatedAt     *UnixTime `json:"created_at,omitempty"`
+	LastUsedAt    *UnixTime `json:"last_used_at,omitempty"`
+}
+
 // CreateServiceAccountRequest represents a request to create a service account
 // Only Name is supported by OpenAI
 // Removed Description field
@@ -182,65 +278,277 @@ func (c *Client) SetConfig(config *Config) error {
 		return fmt.Errorf("organization ID is required")
 	}
 
+	endpoints := config.apiEndpoints()
+	for _, endpoint := range endpoints {
+		if err := validateAPIEndpoint(endpoint); err != nil {
+			return err
+		}
+	}
+
+	headers, err := buildCustomHeaders(config.CustomHeaders, config.SensitiveCustomHeaders)
+	if err != nil {
+		return err
+	}
+
+	openAIBeta := config.OpenAIBeta
+	if openAIBeta == "" {
+		openAIBeta = DefaultOpenAIBeta
+	}
+	if err := validateOpenAIBeta(openAIBeta); err != nil {
+		return err
+	}
+
+	retryPolicy := retryPolicy{
+		Attempts:  config.RequestRetryAttempts,
+		BaseDelay: config.RequestRetryBaseDelay,
+		MaxDelay:  config.RequestRetryMaxDelay,
+	}.withDefaults(defaultRequestRetryPolicy)
+	if err := retryPolicy.validate("request_retry"); err != nil {
+		return err
+	}
+
+	if err := validateAdmissionLimits(config.RateLimit, config.MaxInFlight, config.MaxQueuedRequests); err != nil {
+		return err
+	}
+	if err := validateBreakerSettings(config.BreakerFailureThreshold, config.BreakerCooldown); err != nil {
+		return err
+	}
+
+	httpClient, err := newHTTPClient(config, c.transports)
+	if err != nil {
+		return err
+	}
+
 	c.adminAPIKey = config.AdminAPIKey
 	c.adminAPIKeyID = config.AdminAPIKeyID
 	c.organizationID = config.OrganizationID
+	c.httpClient = httpClient
+	c.headers = headers
+	c.openAIBeta = openAIBeta
+	c.retryPolicy = retryPolicy
+	if c.admissions != nil {
+		c.admission = c.admissions.get(config)
+	} else {
+		c.admission = newAdmissionControl(config.RateLimit, config.MaxInFlight, config.MaxQueuedRequests)
+	}
+	if c.breakers != nil && !config.ownBreaker {
+		c.breaker = c.breakers.get(config)
+	} else {
+		c.breaker = newCircuitBreaker(config.BreakerFailureThreshold, config.BreakerCooldown)
+	}
+	if len(endpoints) > 0 {
+		c.apiEndpoint = endpoints[0]
+	} else {
+		endpoints = []string{c.apiEndpoint}
+	}
+	c.endpoints = newEndpointPool(endpoints, config.EndpointCooldown)
 
+	return nil
+}
+
+// apiEndpoints returns the configured endpoints in order of preference.
+func (config *Config) apiEndpoints() []string {
+	if len(config.APIEndpoints) > 0 {
+		return config.APIEndpoints
+	}
 	if config.APIEndpoint != "" {
-		if err := validateAPIEndpoint(config.APIEndpoint); err != nil {
-			return err
-		}
-		c.apiEndpoint = config.APIEndpoint
+		return []string{config.APIEndpoint}
 	}
-
 	return nil
 }
 
-// doRequest performs an HTTP request with appropriate headers and error handling
-func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
-	var reqBody io.Reader
+// ActiveEndpoint returns the API endpoint the next request will try first.
+func (c *Client) ActiveEndpoint() string {
+	return c.endpoints.active()
+}
+
+// endpointStatus returns the health of each of the client's API endpoints.
+func (c *Client) endpointStatus() []endpointStatus {
+	return c.endpoints.status()
+}
+
+// breakerStatus returns the state of the client's circuit breaker.
+func (c *Client) breakerStatus() breakerStatus {
+	return c.breaker.status()
+}
+
+// doRequest performs an HTTP request with appropriate headers and error
+// handling. Connection errors and 5xx responses fail over to the next
+// configured API endpoint, and failed requests are retried with backoff when
+// retrying is safe; see retryableRequest. While OpenAI keeps failing, the
+// circuit breaker rejects requests without sending them.
+func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (_ []byte, err error) {
+	// Every attempt of this call is sent with the same client request ID.
+	ctx, clientRequestID := withClientRequestID(ctx)
+
+	ctx, span := c.startRequestSpan(ctx, method, path, clientRequestID)
+	var last *endpointResponse
+	var attempts int
+	defer func() { endRequestSpan(span, last, attempts, err) }()
+
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("error marshaling request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	url := c.apiEndpoint + path
+	policy := c.retryPolicy
+	for attempt := 1; ; attempt++ {
+		attempts = attempt
+		if err := c.breaker.allow(); err != nil {
+			return nil, err
+		}
+		release, err := c.admission.acquire(ctx, c.sleep, policy.MaxDelay)
+		if err != nil {
+			c.breaker.abandon()
+			return nil, err
+		}
+		resp, err := c.doFailoverRequest(ctx, method, path, jsonBody)
+		last = resp
+		release()
+		c.breaker.record(ctx, resp.statusCode, err)
+		if err == nil {
+			return resp.body, nil
+		}
+		if attempt >= policy.Attempts || !retryableRequest(ctx, method, resp.statusCode, err) {
+			return nil, err
+		}
+
+		wait := jitter(policy.delay(attempt))
+		if retryAfter := retryAfterDelay(resp.statusCode, resp.header, time.Now()); retryAfter > 0 {
+			// Waiting longer than the policy allows would hold the Vault
+			// request for too long; report the rate limit instead.
+			if retryAfter > policy.MaxDelay {
+				return nil, err
+			}
+			wait = max(wait, retryAfter)
+		}
+
+		c.logger.Warn("OpenAI API request failed, retrying",
+			"method", method,
+			"path", path,
+			"attempt", attempt,
+			"wait", wait,
+			"error", err)
+		if ctxErr := c.sleep(ctx, wait); ctxErr != nil {
+			return nil, fmt.Errorf("%w (last error: %v)", ctxErr, err)
+		}
+	}
+}
+
+// doFailoverRequest performs a request against the API endpoints in order,
+// moving to the next endpoint on connection errors and 5xx responses when
+// that is safe for method. It returns the response of the last endpoint
+// tried.
+func (c *Client) doFailoverRequest(ctx context.Context, method, path string, jsonBody []byte) (*endpointResponse, error) {
+	resp := &endpointResponse{}
+	var err error
+	for _, endpoint := range c.endpoints.candidates() {
+		resp, err = c.doEndpointRequest(ctx, endpoint, method, path, jsonBody)
+		c.admission.observe(resp.statusCode, resp.header)
+		if !shouldFailover(ctx, resp.statusCode, err) {
+			if resp.statusCode != 0 {
+				c.endpoints.markSuccess(endpoint)
+			}
+			return resp, err
+		}
+
+		// The endpoint failed even when the request may not be sent again.
+		c.endpoints.markFailure(endpoint, err)
+		if !retrySafe(method, resp.statusCode, err) {
+			return resp, err
+		}
+		c.logger.Warn("OpenAI API endpoint failed, trying next endpoint",
+			"endpoint", endpoint,
+			"method", method,
+			"path", path,
+			"error", err)
+	}
+	return resp, err
+}
+
+// endpointResponse is the outcome of a request to a single API endpoint.
+type endpointResponse struct {
+	body []byte
+
+	// statusCode is 0 if no response was received.
+	statusCode int
+	header     http.Header
+}
+
+// doEndpointRequest performs a single HTTP request against endpoint. The
+// returned response is never nil.
+func (c *Client) doEndpointRequest(ctx context.Context, endpoint, method, path string, jsonBody []byte) (*endpointResponse, error) {
+	var reqBody io.Reader
+	if jsonBody != nil {
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	url := endpoint + path
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+		return &endpointResponse{}, fmt.Errorf("error creating request: %w", err)
 	}
 
+	// Custom headers go first; reserved headers are rejected at config time,
+	// so they cannot override the ones below.
+	for name, values := range c.headers {
+		req.Header[name] = values
+	}
 	req.Header.Set("Authorization", "Bearer "+c.adminAPIKey)
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "project-service-accounts=v1")
+	req.Header.Set("OpenAI-Beta", c.openAIBeta)
 
 	// Set the organization ID in the header rather than in the URL path
 	if c.organizationID != "" {
 		req.Header.Set("OpenAI-Organization", c.organizationID)
 	}
 
+	clientRequestID := clientRequestIDFromContext(ctx)
+	if clientRequestID != "" {
+		req.Header.Set(clientRequestIDHeader, clientRequestID)
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		c.logger.Debug("OpenAI API request failed",
+			"method", method,
+			"path", path,
+			"client_request_id", clientRequestID,
+			"error", err)
+		return &endpointResponse{}, fmt.Errorf("error making request%s: %w", correlationSuffix("", clientRequestID), err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			c.logger.Error("Failed to close response body", "error", err)
 		}
 	}()
+	result := &endpointResponse{statusCode: resp.StatusCode, header: resp.Header}
+	requestID := resp.Header.Get(requestIDHeader)
+	c.logger.Debug("OpenAI API response",
+		"method", method,
+		"path", path,
+		"status", resp.StatusCode,
+		"request_id", requestID,
+		"client_request_id", clientRequestID)
 
 	// Limit the response body to 1 MiB to prevent memory exhaustion from
 	// oversized or malicious responses.
 	const maxResponseBytes = 1 << 20 // 1 MiB
 	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return result, fmt.Errorf("error reading response body%s: %w", correlationSuffix(requestID, clientRequestID), err)
 	}
 
 	if resp.StatusCode >= 400 {
+		apiErr := &APIError{
+			StatusCode:      resp.StatusCode,
+			RequestID:       requestID,
+			ClientRequestID: clientRequestID,
+		}
 		var errResp struct {
 			Error struct {
 				Message string `json:"message"`
@@ -252,28 +560,21 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 
 		// Try to parse error as OpenAI structured error format
 		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Message != "" {
+			apiErr.Type = errResp.Error.Type
+			apiErr.Code = errResp.Error.Code
+			apiErr.Param = errResp.Error.Param
+			apiErr.Message = errResp.Error.Message
 			c.logger.Error("OpenAI API error",
 				"status", resp.StatusCode,
-				"error_type", errResp.Error.Type,
-				"error_code", errResp.Error.Code,
-				"message", errResp.Error.Message,
-				"param", errResp.Error.Param,
+				"error_type", apiErr.Type,
+				"error_code", apiErr.Code,
+				"message", apiErr.Message,
+				"param", apiErr.Param,
 				"method", method,
-				"path", path)
-
-			// Return error with all available context
-			errMsg := fmt.Sprintf("API error (%d): %s - %s",
-				resp.StatusCode, errResp.Error.Type, errResp.Error.Message)
-
-			if errResp.Error.Code != "" {
-				errMsg += fmt.Sprintf(" (code: %s)", errResp.Error.Code)
-			}
-
-			if errResp.Error.Param != "" {
-				errMsg += fmt.Sprintf(" (param: %s)", errResp.Error.Param)
-			}
-
-			return nil, fmt.Errorf("%s", errMsg)
+				"path", path,
+				"request_id", requestID,
+				"client_request_id", clientRequestID)
+			return result, apiErr
 		}
 
 		// Fallback for non-standard error format. Log a truncated body at debug
@@ -287,11 +588,14 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 			"status", resp.StatusCode,
 			"body_preview", preview,
 			"method", method,
-			"path", path)
-		return nil, fmt.Errorf("API error (%d) from OpenAI", resp.StatusCode)
+			"path", path,
+			"request_id", requestID,
+			"client_request_id", clientRequestID)
+		return result, apiErr
 	}
 
-	return respBody, nil
+	result.body = respBody
+	return result, nil
 }
 
 // ServiceAccountResponse represents the API response for creating a service account.
@@ -338,76 +642,18 @@ func (c *Client) CreateServiceAccount(ctx context.Context, projectID string, req
 		return nil, nil, fmt.Errorf("error creating service account: %w", err)
 	}
 
-	// Parse the exact OpenAI API response format
-	var raw map[string]interface{}
-	if err := json.Unmarshal(respBody, &raw); err == nil {
-		// Initialize service account
-		var svc *ServiceAccount
-
-		// Try to extract from service_account field (nested structure)
-		if serviceAccountData, ok := raw["service_account"].(map[string]interface{}); ok {
-			// Not expected for OpenAI, but fallback for future-proofing
-			svc = &ServiceAccount{
-				ID:        asString(serviceAccountData["id"]),
-				Name:      asString(serviceAccountData["name"]),
-				ProjectID: projectID,
-				Role:      asString(serviceAccountData["role"]),
-			}
-
-			if created, ok := serviceAccountData["created_at"].(float64); ok {
-				t := time.Unix(int64(created), 0)
-				svc.CreatedAt = UnixTimePtr(&t)
-			}
-		} else {
-			// Flat structure (actual OpenAI response)
-			svc = &ServiceAccount{
-				ID:        asString(raw["id"]),
-				Name:      asString(raw["name"]),
-				ProjectID: projectID,
-				Role:      asString(raw["role"]),
-			}
-
-			if created, ok := raw["created_at"].(float64); ok {
-				t := time.Unix(int64(created), 0)
-				svc.CreatedAt = UnixTimePtr(&t)
-			}
-		}
-
-		// Extract the API key from the response
-		if apiKeyData, ok := raw["api_key"].(map[string]interface{}); ok {
-			secretKey := asString(apiKeyData["value"])
-
-			apiKey := &APIKey{
-				ID:           asString(apiKeyData["id"]),
-				Value:        secretKey,
-				Name:         asString(apiKeyData["name"]),
-				ServiceAccID: svc.ID,
-			}
-
-			if created, ok := apiKeyData["created_at"].(float64); ok {
-				t := time.Unix(int64(created), 0)
-				apiKey.CreatedAt = UnixTimePtr(&t)
-			}
-
-			c.logger.Info("Created service account with API key successfully",
-				"service_account_id", svc.ID,
-				"project_id", projectID,
-				"name", svc.Name,
-				"role", svc.Role,
-				"api_key_id", apiKey.ID)
-			return svc, apiKey, nil
-		}
+	svc, apiKey, err := c.decodeCreateServiceAccountResponse(ctx, projectID, respBody)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return nil, nil, fmt.Errorf("service account data missing in API response")
-}
-
-// Helper for fallback parsing
-func asString(v interface{}) string {
-	if s, ok := v.(string); ok {
-		return s
-	}
-	return ""
+	c.logger.Info("Created service account with API key successfully",
+		"service_account_id", svc.ID,
+		"project_id", projectID,
+		"name", svc.Name,
+		"role", svc.Role,
+		"api_key_id", apiKey.ID)
+	return svc, apiKey, nil
 }
 
 // DeleteServiceAccount deletes a service account by ID
@@ -489,29 +735,10 @@ func (c *Client) GetServiceAccount(ctx context.Context, id string, projectID str
 	return &svcAccount, nil
 }
 
-// ListServiceAccounts returns all service accounts for a project
+// ListServiceAccounts returns all service accounts for a project, following
+// every page of the listing.
 func (c *Client) ListServiceAccounts(ctx context.Context, projectID string) ([]*ServiceAccount, error) {
-	if projectID == "" {
-		return nil, fmt.Errorf("project ID is required")
-	}
-	path := fmt.Sprintf(serviceAccountsEndpointFmt, projectID)
-	respBody, err := c.doRequest(ctx, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var result struct {
-		Data []ServiceAccount `json:"data"`
-	}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("error parsing service accounts response: %w", err)
-	}
-
-	accounts := make([]*ServiceAccount, 0, len(result.Data))
-	for i := range result.Data {
-		accounts = append(accounts, &result.Data[i])
-	}
-	return accounts, nil
+	return collect(c.ServiceAccounts(ctx, projectID, ListOptions{}))
 }
 
 // CreateAdminAPIKey creates a new admin API key and returns its value and ID
@@ -525,15 +752,7 @@ func (c *Client) CreateAdminAPIKey(ctx context.Context, name string) (string, st
 	if err != nil {
 		return "", "", fmt.Errorf("error creating admin API key: %w", err)
 	}
-	var result struct {
-		Object        string `json:"object"`
-		ID            string `json:"id"`
-		Name          string `json:"name"`
-		Value         string `json:"value"`
-		CreatedAt     int64  `json:"created_at"`
-		LastUsedAt    int64  `json:"last_used_at"`
-		RedactedValue string `json:"redacted_value"`
-	}
+	var result AdminAPIKey
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		return "", "", fmt.Errorf("error parsing admin API key response: %w", err)
 	}
@@ -558,24 +777,59 @@ func (c *Client) RevokeAdminAPIKey(ctx context.Context, keyID string) error {
 	return nil
 }
 
-// ListAdminAPIKeys lists all admin API keys
-func (c *Client) ListAdminAPIKeys(ctx context.Context) ([]map[string]interface{}, error) {
-	respBody, err := c.doRequest(ctx, http.MethodGet, adminAPIKeysEndpoint, nil)
+// ListAdminAPIKeys lists all admin API keys, following every page of the
+// listing.
+func (c *Client) ListAdminAPIKeys(ctx context.Context) ([]*AdminAPIKey, error) {
+	return collect(c.AdminAPIKeys(ctx, ListOptions{}))
+}
+
+// DiscoverAdminAPIKeyID finds the ID of the client's own admin API key by
+// matching the key against the redacted_value of each admin key in the
+// organization. It fails if no key, or more than one key, matches.
+func (c *Client) DiscoverAdminAPIKeyID(ctx context.Context) (string, error) {
+	keys, err := c.ListAdminAPIKeys(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error listing admin API keys: %w", err)
+		return "", err
+	}
+
+	var matches []string
+	for _, key := range keys {
+		if matchesRedactedValue(c.adminAPIKey, key.RedactedValue) {
+			matches = append(matches, key.ID)
+		}
 	}
-	var result struct {
-		Data []map[string]interface{} `json:"data"`
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no admin API key in the organization matches the configured admin_api_key")
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%d admin API keys match the configured admin_api_key; set admin_api_key_id explicitly (candidates: %s)",
+			len(matches), strings.Join(matches, ", "))
 	}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("error parsing admin API keys response: %w", err)
+}
+
+// matchesRedactedValue reports whether key is consistent with a redacted key
+// as OpenAI reports it, e.g. "sk-admin...abc": the visible prefix and suffix
+// must both match. A redacted value without an ellipsis never matches.
+func matchesRedactedValue(key, redacted string) bool {
+	for _, sep := range []string{"...", "\u2026"} {
+		prefix, suffix, found := strings.Cut(redacted, sep)
+		if !found {
+			continue
+		}
+		return len(key) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(key, prefix) &&
+			strings.HasSuffix(key, suffix)
 	}
-	return result.Data, nil
+	return false
 }
 
-// TestConnection tests the client connection by listing admin API keys
+// TestConnection tests the client connection by listing a single admin API
+// key.
 func (c *Client) TestConnection(ctx context.Context) error {
-	_, err := c.ListAdminAPIKeys(ctx)
+	_, err := c.ListAdminAPIKeysPage(ctx, ListOptions{Limit: 1})
 	if err != nil {
 		return fmt.Errorf("admin API key validation failed: %w", err)
 	}
@@ -597,7 +851,7 @@ func (c *Client) ValidateProject(ctx context.Context, projectID string) error {
 }
 
 // GetAdminAPIKey retrieves details for a specific admin API key by ID.
-func (c *Client) GetAdminAPIKey(ctx context.Context, keyID string) (map[string]interface{}, error) {
+func (c *Client) GetAdminAPIKey(ctx context.Context, keyID string) (*AdminAPIKey, error) {
 	if keyID == "" {
 		return nil, fmt.Errorf("admin API key ID is required")
 	}
@@ -606,11 +860,11 @@ func (c *Client) GetAdminAPIKey(ctx context.Context, keyID string) (map[string]i
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving admin API key details: %w", err)
 	}
-	var result map[string]interface{}
+	var result AdminAPIKey
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		return nil, fmt.Errorf("error parsing admin API key details: %w", err)
 	}
-	return result, nil
+	return &result, nil
 }
 
 // ProjectInfo represents the OpenAI project details response
@@ -120,3 +120,99 @@ func TestCreateAdminAPIKeyMissingValue(t *testing.T) { // Create a test server t
 		t.Fatal("Expected an error for missing value field, got nil")
 	}
 }
+
+func TestMatchesRedactedValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		redacted string
+		expected bool
+	}{
+		{"prefix and suffix match", "sk-admin-1234abcd", "sk-admin...bcd", true},
+		{"unicode ellipsis", "sk-admin-1234abcd", "sk-admin…bcd", true},
+		{"suffix differs", "sk-admin-1234abcd", "sk-admin...xyz", false},
+		{"prefix differs", "sk-admin-1234abcd", "sk-proj...bcd", false},
+		{"overlapping prefix and suffix", "sk-ab", "sk-ab...ab", false},
+		{"no ellipsis", "sk-admin-1234abcd", "sk-admin-1234abcd", false},
+		{"empty redacted value", "sk-admin-1234abcd", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesRedactedValue(tt.key, tt.redacted); got != tt.expected {
+				t.Errorf("matchesRedactedValue(%q, %q) = %v, want %v", tt.key, tt.redacted, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiscoverAdminAPIKeyID(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+	mockServer.AddAdminAPIKey("key_other", "other-admin-key", "sk-admin-other-0000")
+
+	client := NewClient(TestAPIKey, hclog.NewNullLogger())
+	if err := client.SetConfig(&Config{
+		AdminAPIKey:    TestAPIKey,
+		APIEndpoint:    mockServer.URL() + "/v1",
+		OrganizationID: TestOrganizationID,
+	}); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+
+	keyID, err := client.DiscoverAdminAPIKeyID(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if keyID != TestAdminAPIKeyID {
+		t.Errorf("Expected key ID %q, got %q", TestAdminAPIKeyID, keyID)
+	}
+
+	// A second key with the same visible prefix and suffix is ambiguous.
+	mockServer.AddAdminAPIKey("key_duplicate", "duplicate-admin-key", TestAPIKey)
+	if _, err := client.DiscoverAdminAPIKeyID(context.Background()); err == nil {
+		t.Fatal("Expected an error for an ambiguous match, got nil")
+	}
+
+	// A key that is not in the organization cannot be discovered.
+	unknown := NewClient("sk-admin-unknown-9999", hclog.NewNullLogger())
+	_ = unknown.SetConfig(&Config{
+		AdminAPIKey:    "sk-admin-unknown-9999",
+		APIEndpoint:    mockServer.URL() + "/v1",
+		OrganizationID: TestOrganizationID,
+	})
+	if _, err := unknown.DiscoverAdminAPIKeyID(context.Background()); err == nil {
+		t.Fatal("Expected an error when no key matches, got nil")
+	}
+}
+
+func TestGetAdminAPIKey_Typed(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	client := NewClient(TestAPIKey, hclog.NewNullLogger())
+	if err := client.SetConfig(&Config{
+		AdminAPIKey:    TestAPIKey,
+		APIEndpoint:    mockServer.URL() + "/v1",
+		OrganizationID: TestOrganizationID,
+	}); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+
+	key, err := client.GetAdminAPIKey(context.Background(), TestAdminAPIKeyID)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if key.ID != TestAdminAPIKeyID || key.Name != "sample-admin-key" {
+		t.Errorf("Unexpected key %q (%q)", key.ID, key.Name)
+	}
+	if !matchesRedactedValue(TestAPIKey, key.RedactedValue) {
+		t.Errorf("Redacted value %q does not match the key", key.RedactedValue)
+	}
+	if key.Value != "" {
+		t.Error("Expected the key value to be withheld")
+	}
+	if key.CreatedAt == nil || key.CreatedAt.Time().IsZero() {
+		t.Error("Expected created_at to be decoded")
+	}
+}
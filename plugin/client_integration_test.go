@@ -101,6 +101,8 @@ func TestClientIntegration_ErrorHandling(t *testing.T) {
 		AdminAPIKey:    "test-key",
 		APIEndpoint:    mockServer.URL() + "/v1",
 		OrganizationID: "org-123",
+		// Surface each injected failure instead of retrying it.
+		RequestRetryAttempts: 1,
 	})
 	require.NoError(t, err)
 
@@ -0,0 +1,99 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	uuid "github.com/hashicorp/go-uuid"
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	// clientRequestIDHeader carries the ID the client assigns to each OpenAI
+	// API call. OpenAI records it and can look a request up by it.
+	clientRequestIDHeader = "X-Client-Request-Id"
+
+	// requestIDHeader carries the ID OpenAI assigns to each request. OpenAI
+	// support asks for it when investigating a failure.
+	requestIDHeader = "X-Request-Id"
+
+	// maxClientRequestIDLength is the longest X-Client-Request-Id OpenAI
+	// accepts.
+	maxClientRequestIDLength = 512
+)
+
+type vaultRequestIDKey struct{}
+
+type clientRequestIDKey struct{}
+
+// withVaultRequestID returns a context carrying the ID of the Vault request
+// being handled, from which client request IDs are derived.
+func withVaultRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, vaultRequestIDKey{}, id)
+}
+
+// withClientRequestID returns a context carrying the client request ID for a
+// single OpenAI API call, generating one if ctx does not carry it yet.
+// Retries and failover attempts of the call share the ID.
+func withClientRequestID(ctx context.Context) (context.Context, string) {
+	if id := clientRequestIDFromContext(ctx); id != "" {
+		return ctx, id
+	}
+	id := newClientRequestID(ctx)
+	return context.WithValue(ctx, clientRequestIDKey{}, id), id
+}
+
+// clientRequestIDFromContext returns the client request ID carried by ctx, or
+// an empty string.
+func clientRequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(clientRequestIDKey{}).(string)
+	return id
+}
+
+// newClientRequestID returns a client request ID. Within a Vault request it
+// is the Vault request ID followed by a random suffix, since one Vault
+// request may make several API calls; otherwise, as for background rotation,
+// it is a random UUID.
+func newClientRequestID(ctx context.Context) string {
+	vaultID, _ := ctx.Value(vaultRequestIDKey{}).(string)
+	if vaultID != "" {
+		suffix, err := uuid.GenerateRandomBytes(4)
+		if err == nil {
+			id := vaultID + "-" + hex.EncodeToString(suffix)
+			if len(id) <= maxClientRequestIDLength && httpguts.ValidHeaderFieldValue(id) {
+				return id
+			}
+		}
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		// The header is informational; a request is never failed for it.
+		return ""
+	}
+	return id
+}
+
+// correlationSuffix formats the request IDs of an API call for error
+// messages, omitting the ones that are not known.
+func correlationSuffix(requestID, clientRequestID string) string {
+	var ids []string
+	if requestID != "" {
+		ids = append(ids, "request_id: "+requestID)
+	}
+	if clientRequestID != "" {
+		ids = append(ids, "client_request_id: "+clientRequestID)
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(ids, ", "))
+}
@@ -0,0 +1,165 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRequestID(t *testing.T) {
+	ctx := withVaultRequestID(context.Background(), "vault-req-1")
+	ctx, id := withClientRequestID(ctx)
+	assert.True(t, strings.HasPrefix(id, "vault-req-1-"), id)
+	assert.Len(t, id, len("vault-req-1-")+8)
+
+	// An ID already in the context is kept.
+	_, again := withClientRequestID(ctx)
+	assert.Equal(t, id, again)
+
+	// Each API call of a Vault request gets an ID of its own.
+	_, other := withClientRequestID(withVaultRequestID(context.Background(), "vault-req-1"))
+	assert.NotEqual(t, id, other)
+
+	// Without a Vault request, a random ID is used.
+	_, id = withClientRequestID(context.Background())
+	assert.Len(t, id, 36)
+
+	_, id = withClientRequestID(withVaultRequestID(context.Background(), "bad\nid"))
+	assert.Len(t, id, 36, "IDs that cannot be sent as a header are not used")
+}
+
+func TestCorrelationSuffix(t *testing.T) {
+	assert.Equal(t, "", correlationSuffix("", ""))
+	assert.Equal(t, " (request_id: req_1)", correlationSuffix("req_1", ""))
+	assert.Equal(t, " (request_id: req_1, client_request_id: c1)", correlationSuffix("req_1", "c1"))
+
+	err := &APIError{StatusCode: http.StatusBadGateway, RequestID: "req_1", ClientRequestID: "c1"}
+	assert.Equal(t, "API error (502) from OpenAI (request_id: req_1, client_request_id: c1)", err.Error())
+}
+
+func TestClient_CorrelationIDs(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent = append(sent, r.Header.Get(clientRequestIDHeader))
+		mu.Unlock()
+		w.Header().Set("X-Request-Id", "req_abc123")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error": {"message": "boom", "type": "server_error"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(TestAPIKey, hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:          TestAPIKey,
+		APIEndpoint:          server.URL,
+		OrganizationID:       TestOrganizationID,
+		RequestRetryAttempts: 3,
+	}))
+	client.sleep = func(context.Context, time.Duration) error { return nil }
+
+	ctx := withVaultRequestID(context.Background(), "vault-req-1")
+	_, err := client.ListAdminAPIKeys(ctx)
+	require.Error(t, err)
+
+	require.Len(t, sent, 3)
+	assert.True(t, strings.HasPrefix(sent[0], "vault-req-1-"), sent[0])
+	assert.Equal(t, sent[0], sent[1], "retries reuse the client request ID")
+	assert.Equal(t, sent[0], sent[2])
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "req_abc123", apiErr.RequestID)
+	assert.Equal(t, sent[0], apiErr.ClientRequestID)
+	assert.Contains(t, err.Error(), "request_id: req_abc123")
+	assert.Contains(t, err.Error(), "client_request_id: "+sent[0])
+}
+
+func TestClient_CorrelationIDsOnTransportError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client := NewClient(TestAPIKey, hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:          TestAPIKey,
+		APIEndpoint:          url,
+		OrganizationID:       TestOrganizationID,
+		RequestRetryAttempts: 1,
+	}))
+
+	_, err := client.ListAdminAPIKeys(withVaultRequestID(context.Background(), "vault-req-2"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error making request (client_request_id: vault-req-2-")
+}
+
+func TestBuildCustomHeaders_ClientRequestIDReserved(t *testing.T) {
+	_, err := buildCustomHeaders(map[string]string{"x-client-request-id": "mine"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reserved")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBackend_PassesVaultRequestID(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+
+	var mu sync.Mutex
+	var sent []string
+	b.newClient = func(config *Config, logger hclog.Logger) (ClientAPI, error) {
+		client, err := newOpenAIClient(b.transports, b.admissions, b.breakers, b.tracerProvider)(config, logger)
+		if err != nil {
+			return nil, err
+		}
+		c := client.(*Client)
+		transport := c.httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		c.httpClient.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			sent = append(sent, req.Header.Get(clientRequestIDHeader))
+			mu.Unlock()
+			return transport.RoundTrip(req)
+		})
+		return c, nil
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		ID:        "vault-req-3",
+		Operation: logical.ReadOperation,
+		Path:      "config/health",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, healthStatusHealthy, resp.Data["status"])
+
+	require.NotEmpty(t, sent)
+	for _, id := range sent {
+		assert.True(t, strings.HasPrefix(id, "vault-req-3-"), id)
+	}
+}
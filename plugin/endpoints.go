@@ -0,0 +1,158 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultEndpointCooldown is how long an API endpoint is skipped after a
+// connection error or 5xx response, when other endpoints are available.
+const DefaultEndpointCooldown = 30 * time.Second
+
+// endpointPool tracks the health of a client's API endpoints, which are kept
+// in the operator's order of preference.
+type endpointPool struct {
+	mu        sync.Mutex
+	endpoints []*endpointState
+	cooldown  time.Duration
+
+	// now is overridden in tests.
+	now func() time.Time
+}
+
+// endpointState is the health of a single API endpoint.
+type endpointState struct {
+	url            string
+	failures       int
+	lastError      string
+	unhealthyUntil time.Time
+}
+
+// endpointStatus is a snapshot of an endpoint's health for config reads.
+type endpointStatus struct {
+	URL            string
+	Healthy        bool
+	Failures       int
+	LastError      string
+	UnhealthyUntil time.Time
+}
+
+// newEndpointPool returns a pool for urls, in order of preference.
+func newEndpointPool(urls []string, cooldown time.Duration) *endpointPool {
+	if cooldown <= 0 {
+		cooldown = DefaultEndpointCooldown
+	}
+	p := &endpointPool{cooldown: cooldown, now: time.Now}
+	for _, u := range urls {
+		p.endpoints = append(p.endpoints, &endpointState{url: u})
+	}
+	return p
+}
+
+// candidates returns the endpoints in the order a request should try them:
+// healthy endpoints in order of preference, then endpoints in cooldown,
+// soonest to recover first. Endpoints in cooldown are still tried last so a
+// request is never refused outright.
+func (p *endpointPool) candidates() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var healthy []string
+	var cooling []*endpointState
+	for _, e := range p.endpoints {
+		if now.Before(e.unhealthyUntil) {
+			cooling = append(cooling, e)
+			continue
+		}
+		healthy = append(healthy, e.url)
+	}
+	sort.SliceStable(cooling, func(i, j int) bool {
+		return cooling[i].unhealthyUntil.Before(cooling[j].unhealthyUntil)
+	})
+	for _, e := range cooling {
+		healthy = append(healthy, e.url)
+	}
+	return healthy
+}
+
+// active returns the endpoint the next request will use first.
+func (p *endpointPool) active() string {
+	candidates := p.candidates()
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[0]
+}
+
+// markSuccess records that url answered a request.
+func (p *endpointPool) markSuccess(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e := p.find(url); e != nil {
+		e.failures = 0
+		e.lastError = ""
+		e.unhealthyUntil = time.Time{}
+	}
+}
+
+// markFailure puts url into cooldown after a connection error or 5xx.
+func (p *endpointPool) markFailure(url string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e := p.find(url); e != nil {
+		e.failures++
+		e.lastError = err.Error()
+		e.unhealthyUntil = p.now().Add(p.cooldown)
+	}
+}
+
+// status returns a snapshot of every endpoint's health, in order of
+// preference.
+func (p *endpointPool) status() []endpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	statuses := make([]endpointStatus, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		statuses = append(statuses, endpointStatus{
+			URL:            e.url,
+			Healthy:        !now.Before(e.unhealthyUntil),
+			Failures:       e.failures,
+			LastError:      e.lastError,
+			UnhealthyUntil: e.unhealthyUntil,
+		})
+	}
+	return statuses
+}
+
+// find returns the state for url. The caller must hold p.mu.
+func (p *endpointPool) find(url string) *endpointState {
+	for _, e := range p.endpoints {
+		if e.url == url {
+			return e
+		}
+	}
+	return nil
+}
+
+// shouldFailover reports whether a request that got statusCode or err should
+// be retried on the next endpoint: connection errors and 5xx responses are
+// endpoint failures, anything else is an answer from OpenAI.
+func shouldFailover(ctx context.Context, statusCode int, err error) bool {
+	// The caller gave up; trying another endpoint cannot help.
+	if ctx.Err() != nil {
+		return false
+	}
+	if statusCode == 0 {
+		return err != nil
+	}
+	return statusCode >= http.StatusInternalServerError
+}
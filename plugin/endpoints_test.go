@@ -0,0 +1,266 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointPool_Candidates(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	pool := newEndpointPool([]string{"https://a", "https://b", "https://c"}, time.Minute)
+	pool.now = func() time.Time { return now }
+
+	assert.Equal(t, []string{"https://a", "https://b", "https://c"}, pool.candidates())
+
+	// Failed endpoints move behind healthy ones, soonest to recover first.
+	pool.markFailure("https://a", errors.New("connection refused"))
+	now = now.Add(10 * time.Second)
+	pool.markFailure("https://b", errors.New("502"))
+	assert.Equal(t, []string{"https://c", "https://a", "https://b"}, pool.candidates())
+	assert.Equal(t, "https://c", pool.active())
+
+	status := pool.status()
+	require.Len(t, status, 3)
+	assert.False(t, status[0].Healthy)
+	assert.Equal(t, 1, status[0].Failures)
+	assert.Equal(t, "connection refused", status[0].LastError)
+	assert.True(t, status[2].Healthy)
+
+	// Once the cooldown has passed the preferred order is restored.
+	now = now.Add(time.Minute)
+	assert.Equal(t, []string{"https://a", "https://b", "https://c"}, pool.candidates())
+
+	pool.markSuccess("https://a")
+	assert.Equal(t, 0, pool.status()[0].Failures)
+	assert.Empty(t, pool.status()[0].LastError)
+}
+
+func TestShouldFailover(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, shouldFailover(ctx, 0, errors.New("connection refused")))
+	assert.True(t, shouldFailover(ctx, http.StatusBadGateway, errors.New("bad gateway")))
+	assert.False(t, shouldFailover(ctx, http.StatusNotFound, errors.New("not found")))
+	assert.False(t, shouldFailover(ctx, http.StatusOK, nil))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, shouldFailover(cancelled, 0, context.Canceled))
+}
+
+// endpointTestServer returns a server answering every request with status
+// and counting the requests it receives.
+func endpointTestServer(t *testing.T, status int, hits *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"object": "list", "data": []}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_EndpointFailover(t *testing.T) {
+	var downHits, upHits int32
+	down := endpointTestServer(t, http.StatusBadGateway, &downHits)
+	up := endpointTestServer(t, http.StatusOK, &upHits)
+
+	client := NewClient("test-key", hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:    "test-key",
+		OrganizationID: "org-123",
+		APIEndpoint:    down.URL,
+		APIEndpoints:   []string{down.URL, up.URL},
+	}))
+	assert.Equal(t, down.URL, client.apiEndpoint)
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&downHits))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&upHits))
+	assert.Equal(t, up.URL, client.ActiveEndpoint())
+
+	// The failed endpoint is in cooldown, so the next request skips it.
+	_, err = client.ListAdminAPIKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&downHits))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&upHits))
+}
+
+func TestClient_EndpointFailover_ConnectionError(t *testing.T) {
+	var upHits int32
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	up := endpointTestServer(t, http.StatusOK, &upHits)
+
+	client := NewClient("test-key", hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:    "test-key",
+		OrganizationID: "org-123",
+		APIEndpoints:   []string{closed.URL, up.URL},
+	}))
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&upHits))
+
+	status := client.endpointStatus()
+	require.Len(t, status, 2)
+	assert.False(t, status[0].Healthy)
+	assert.Contains(t, status[0].LastError, "error making request")
+	assert.True(t, status[1].Healthy)
+}
+
+func TestClient_EndpointFailover_ClientErrorIsNotRetried(t *testing.T) {
+	var firstHits, secondHits int32
+	first := endpointTestServer(t, http.StatusUnauthorized, &firstHits)
+	second := endpointTestServer(t, http.StatusOK, &secondHits)
+
+	client := NewClient("test-key", hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:    "test-key",
+		OrganizationID: "org-123",
+		APIEndpoints:   []string{first.URL, second.URL},
+	}))
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&secondHits))
+	assert.Equal(t, first.URL, client.ActiveEndpoint())
+}
+
+func TestClient_EndpointFailover_PostServerErrorMarksFailure(t *testing.T) {
+	var firstHits, secondHits int32
+	first := endpointTestServer(t, http.StatusInternalServerError, &firstHits)
+	second := endpointTestServer(t, http.StatusOK, &secondHits)
+
+	client := NewClient("test-key", hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:          "test-key",
+		OrganizationID:       "org-123",
+		APIEndpoints:         []string{first.URL, second.URL},
+		RequestRetryAttempts: 1,
+	}))
+
+	// A POST that may have been processed is not sent to the next endpoint,
+	// but the endpoint that failed it is still marked unhealthy.
+	_, _, err := client.CreateAdminAPIKey(context.Background(), "vault-admin-key-test")
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&firstHits))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&secondHits))
+
+	status := client.endpointStatus()
+	require.Len(t, status, 2)
+	assert.False(t, status[0].Healthy)
+	assert.Contains(t, status[0].LastError, "500")
+	assert.Equal(t, second.URL, client.ActiveEndpoint())
+}
+
+func TestClient_EndpointFailover_AllEndpointsDown(t *testing.T) {
+	var firstHits, secondHits int32
+	first := endpointTestServer(t, http.StatusServiceUnavailable, &firstHits)
+	second := endpointTestServer(t, http.StatusInternalServerError, &secondHits)
+
+	client := NewClient("test-key", hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:          "test-key",
+		OrganizationID:       "org-123",
+		APIEndpoints:         []string{first.URL, second.URL},
+		RequestRetryAttempts: 1,
+	}))
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "500")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&firstHits))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&secondHits))
+}
+
+func TestClient_SetConfig_InvalidFailoverEndpoint(t *testing.T) {
+	client := NewClient("test-key", hclog.NewNullLogger())
+	err := client.SetConfig(&Config{
+		AdminAPIKey:    "test-key",
+		OrganizationID: "org-123",
+		APIEndpoints:   []string{"https://api.openai.com/v1", "ftp://egress.example.com"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http or https")
+}
+
+func TestConfig_APIEndpointList(t *testing.T) {
+	b := getTestBackend(t)
+	offlineAdminKeyLookups(b)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	req := &logical.Request{Storage: storage, MountPoint: TestMountPoint, Path: "config"}
+
+	resp, err := b.pathConfigWrite(ctx, req, &framework.FieldData{
+		Raw: map[string]interface{}{
+			"admin_api_key":     TestAPIKey,
+			"admin_api_key_id":  TestAdminAPIKeyID,
+			"organization_id":   TestOrganizationID,
+			"api_endpoint":      "https://egress-a.example.com/v1, https://egress-b.example.com/v1",
+			"endpoint_cooldown": 60,
+			"verify_connection": false,
+		},
+		Schema: b.pathAdminConfig()[1].Fields,
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	config, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, "https://egress-a.example.com/v1", config.APIEndpoint)
+	assert.Equal(t, []string{"https://egress-a.example.com/v1", "https://egress-b.example.com/v1"}, config.APIEndpoints)
+	assert.Equal(t, time.Minute, config.EndpointCooldown)
+
+	resp, err = b.pathConfigRead(ctx, req, &framework.FieldData{})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, "https://egress-a.example.com/v1", resp.Data["api_endpoint"])
+	assert.Equal(t, config.APIEndpoints, resp.Data["api_endpoints"])
+	assert.Equal(t, int64(60), resp.Data["endpoint_cooldown"])
+	assert.Equal(t, "https://egress-a.example.com/v1", resp.Data["active_endpoint"])
+
+	// The active endpoint follows the cached client's endpoint health.
+	client := b.getClient(defaultConnectionName).(*Client)
+	client.endpoints.markFailure("https://egress-a.example.com/v1", errors.New("connection refused"))
+	resp, err = b.pathConfigRead(ctx, req, &framework.FieldData{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://egress-b.example.com/v1", resp.Data["active_endpoint"])
+	health := resp.Data["endpoint_health"].([]map[string]interface{})
+	require.Len(t, health, 2)
+	assert.Equal(t, false, health[0]["healthy"])
+	assert.Equal(t, "connection refused", health[0]["last_error"])
+	assert.Equal(t, true, health[1]["healthy"])
+
+	// Writing a single endpoint drops the failover list.
+	resp, err = b.pathConfigWrite(ctx, req, &framework.FieldData{
+		Raw: map[string]interface{}{
+			"api_endpoint":      "https://api.test.com/v1",
+			"verify_connection": false,
+		},
+		Schema: b.pathAdminConfig()[1].Fields,
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	config, err = getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.test.com/v1", config.APIEndpoint)
+	assert.Nil(t, config.APIEndpoints)
+}
@@ -0,0 +1,75 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinels matched by APIError with errors.Is, so callers can react to the
+// kind of failure without inspecting status codes.
+var (
+	// ErrNotFound matches an OpenAI API error with status 404.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized matches an OpenAI API error with status 401.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrRateLimited matches an OpenAI API error with status 429.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// ErrUnexpectedResponse is returned when a successful OpenAI API response
+// cannot be used because it lacks required fields or cannot be parsed.
+var ErrUnexpectedResponse = errors.New("unexpected response from OpenAI")
+
+// APIError is an error response from the OpenAI API. Type, Code, Param and
+// Message are taken from the response's error object and are empty when the
+// response did not contain one. RequestID is the ID OpenAI assigned to the
+// request and ClientRequestID the one the client sent; quote them when
+// contacting OpenAI support.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Code       string
+	Param      string
+	Message    string
+
+	RequestID       string
+	ClientRequestID string
+}
+
+// Error formats the error with all available context. Responses without an
+// error object only report the status, so unexpected bodies never end up in
+// error messages.
+func (e *APIError) Error() string {
+	ids := correlationSuffix(e.RequestID, e.ClientRequestID)
+	if e.Message == "" {
+		return fmt.Sprintf("API error (%d) from OpenAI", e.StatusCode) + ids
+	}
+	msg := fmt.Sprintf("API error (%d): %s - %s", e.StatusCode, e.Type, e.Message)
+	if e.Code != "" {
+		msg += fmt.Sprintf(" (code: %s)", e.Code)
+	}
+	if e.Param != "" {
+		msg += fmt.Sprintf(" (param: %s)", e.Param)
+	}
+	return msg + ids
+}
+
+// Is reports whether e matches one of the sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
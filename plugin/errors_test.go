@@ -0,0 +1,94 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError(t *testing.T) {
+	err := &APIError{StatusCode: http.StatusNotFound, Type: "invalid_request_error", Message: "No such service account", Code: "not_found", Param: "id"}
+	assert.Equal(t, "API error (404): invalid_request_error - No such service account (code: not_found) (param: id)", err.Error())
+	assert.Equal(t, "API error (502) from OpenAI", (&APIError{StatusCode: http.StatusBadGateway}).Error())
+
+	wrapped := fmt.Errorf("error deleting service account: %w", err)
+	assert.True(t, errors.Is(wrapped, ErrNotFound))
+	assert.False(t, errors.Is(wrapped, ErrUnauthorized))
+
+	var apiErr *APIError
+	require.True(t, errors.As(wrapped, &apiErr))
+	assert.Equal(t, "not_found", apiErr.Code)
+
+	assert.True(t, errors.Is(&APIError{StatusCode: http.StatusUnauthorized}, ErrUnauthorized))
+	assert.True(t, errors.Is(&APIError{StatusCode: http.StatusTooManyRequests}, ErrRateLimited))
+	assert.False(t, errors.Is(&APIError{StatusCode: http.StatusInternalServerError}, ErrNotFound))
+}
+
+func TestClient_ReturnsAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		sentinel error
+		code     string
+	}{
+		{"not found", http.StatusNotFound, `{"error": {"message": "missing", "type": "invalid_request_error", "code": "not_found"}}`, ErrNotFound, "not_found"},
+		{"unauthorized", http.StatusUnauthorized, `{"error": {"message": "bad key", "type": "invalid_request_error", "code": "invalid_api_key"}}`, ErrUnauthorized, "invalid_api_key"},
+		{"rate limited", http.StatusTooManyRequests, `{"error": {"message": "slow down", "type": "requests"}}`, ErrRateLimited, ""},
+		{"non-standard body", http.StatusNotFound, `<html>not found</html>`, ErrNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(TestAPIKey, hclog.NewNullLogger())
+			require.NoError(t, client.SetConfig(&Config{
+				AdminAPIKey:          TestAPIKey,
+				APIEndpoint:          server.URL,
+				OrganizationID:       TestOrganizationID,
+				RequestRetryAttempts: 1,
+			}))
+
+			err := client.DeleteServiceAccount(context.Background(), "svc_123", TestProjectID)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tt.sentinel))
+
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.code, apiErr.Code)
+			assert.NotContains(t, err.Error(), "<html>")
+		})
+	}
+}
+
+func TestClient_MockServerNotFound(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	client := NewClient(TestAPIKey, hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:    TestAPIKey,
+		APIEndpoint:    mockServer.URL() + "/v1",
+		OrganizationID: TestOrganizationID,
+	}))
+
+	_, err := client.GetServiceAccount(context.Background(), "svc_missing", TestProjectID)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,85 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// DefaultOpenAIBeta is the OpenAI-Beta header value sent when openai_beta is
+// not configured.
+const DefaultOpenAIBeta = "project-service-accounts=v1"
+
+// reservedHeaders are set by the client itself and cannot be overridden by
+// custom headers. OpenAI-Beta is configured through openai_beta instead.
+var reservedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Connection":          true,
+	"Content-Length":      true,
+	"Content-Type":        true,
+	"Host":                true,
+	"Openai-Beta":         true,
+	"Openai-Organization": true,
+	"Transfer-Encoding":   true,
+	"X-Client-Request-Id": true,
+}
+
+// validateOpenAIBeta checks that value can be sent as the OpenAI-Beta header.
+func validateOpenAIBeta(value string) error {
+	if !httpguts.ValidHeaderFieldValue(value) {
+		return fmt.Errorf("openai_beta contains characters not allowed in a header value")
+	}
+	return nil
+}
+
+// buildCustomHeaders validates the static and sensitive custom headers and
+// merges them into one header set. Header names are case-insensitive, so a
+// name may only appear once across both sets.
+func buildCustomHeaders(headers, sensitiveHeaders map[string]string) (http.Header, error) {
+	built := make(http.Header, len(headers)+len(sensitiveHeaders))
+	for _, set := range []struct {
+		field   string
+		headers map[string]string
+	}{
+		{"custom_headers", headers},
+		{"sensitive_custom_headers", sensitiveHeaders},
+	} {
+		// Sort so the first invalid header is reported deterministically.
+		for _, name := range sortedHeaderNames(set.headers) {
+			value := set.headers[name]
+			if !httpguts.ValidHeaderFieldName(name) {
+				return nil, fmt.Errorf("%s: %q is not a valid header name", set.field, name)
+			}
+			canonical := http.CanonicalHeaderKey(name)
+			if reservedHeaders[canonical] {
+				return nil, fmt.Errorf("%s: header %q is reserved and cannot be overridden", set.field, name)
+			}
+			if !httpguts.ValidHeaderFieldValue(value) {
+				return nil, fmt.Errorf("%s: header %q has a value with characters not allowed in a header", set.field, name)
+			}
+			if _, ok := built[canonical]; ok {
+				return nil, fmt.Errorf("%s: header %q is configured more than once", set.field, name)
+			}
+			built.Set(canonical, value)
+		}
+	}
+	return built, nil
+}
+
+// sortedHeaderNames returns the names of headers in sorted order.
+func sortedHeaderNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+	return names
+}
@@ -0,0 +1,156 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCustomHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		sensitive   map[string]string
+		errContains string
+	}{
+		{"none", nil, nil, ""},
+		{"static and sensitive", map[string]string{"X-Team": "ml"}, map[string]string{"X-Gateway-Key": "secret"}, ""},
+		{"reserved authorization", map[string]string{"authorization": "Bearer x"}, nil, "reserved"},
+		{"reserved beta", nil, map[string]string{"OpenAI-Beta": "assistants=v2"}, "reserved"},
+		{"reserved organization", map[string]string{"OpenAI-Organization": "org-456"}, nil, "reserved"},
+		{"reserved host", map[string]string{"Host": "evil.example.com"}, nil, "reserved"},
+		{"invalid name", map[string]string{"X Bad": "v"}, nil, "not a valid header name"},
+		{"header injection", map[string]string{"X-Team": "ml\r\nX-Injected: 1"}, nil, "not allowed"},
+		{"duplicate across sets", map[string]string{"X-Gateway-Key": "a"}, map[string]string{"x-gateway-key": "b"}, "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, err := buildCustomHeaders(tt.headers, tt.sensitive)
+			if tt.errContains == "" {
+				require.NoError(t, err)
+				assert.Len(t, headers, len(tt.headers)+len(tt.sensitive))
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestValidateOpenAIBeta(t *testing.T) {
+	require.NoError(t, validateOpenAIBeta("project-service-accounts=v2"))
+	require.Error(t, validateOpenAIBeta("v1\r\nX-Injected: 1"))
+}
+
+func TestClient_SendsCustomHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object": "list", "data": []}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:            "test-key",
+		OrganizationID:         "org-123",
+		APIEndpoint:            server.URL,
+		CustomHeaders:          map[string]string{"x-team": "ml"},
+		SensitiveCustomHeaders: map[string]string{"X-Gateway-Key": "secret"},
+		OpenAIBeta:             "project-service-accounts=v2",
+	}))
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ml", got.Get("X-Team"))
+	assert.Equal(t, "secret", got.Get("X-Gateway-Key"))
+	assert.Equal(t, "project-service-accounts=v2", got.Get("OpenAI-Beta"))
+	assert.Equal(t, "Bearer test-key", got.Get("Authorization"))
+	assert.Equal(t, "org-123", got.Get("OpenAI-Organization"))
+}
+
+func TestClient_DefaultOpenAIBeta(t *testing.T) {
+	var beta string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		beta = r.Header.Get("OpenAI-Beta")
+		_, _ = w.Write([]byte(`{"object": "list", "data": []}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:    "test-key",
+		OrganizationID: "org-123",
+		APIEndpoint:    server.URL,
+	}))
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultOpenAIBeta, beta)
+}
+
+func TestConfig_CustomHeaders(t *testing.T) {
+	b := getTestBackend(t)
+	offlineAdminKeyLookups(b)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	req := &logical.Request{Storage: storage, MountPoint: TestMountPoint, Path: "config"}
+
+	write := func(raw map[string]interface{}) *logical.Response {
+		raw["verify_connection"] = false
+		resp, err := b.pathConfigWrite(ctx, req, &framework.FieldData{
+			Raw:    raw,
+			Schema: b.pathAdminConfig()[1].Fields,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := write(map[string]interface{}{
+		"admin_api_key":            TestAPIKey,
+		"admin_api_key_id":         TestAdminAPIKeyID,
+		"organization_id":          TestOrganizationID,
+		"custom_headers":           map[string]interface{}{"X-Team": "ml"},
+		"sensitive_custom_headers": []interface{}{"X-Gateway-Key=secret"},
+		"openai_beta":              "project-service-accounts=v2",
+	})
+	require.Nil(t, resp)
+
+	config, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Team": "ml"}, config.CustomHeaders)
+	assert.Equal(t, map[string]string{"X-Gateway-Key": "secret"}, config.SensitiveCustomHeaders)
+
+	resp, err = b.pathConfigRead(ctx, req, &framework.FieldData{})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, map[string]string{"X-Team": "ml"}, resp.Data["custom_headers"])
+	assert.Equal(t, []string{"X-Gateway-Key"}, resp.Data["sensitive_custom_headers"])
+	assert.Equal(t, "project-service-accounts=v2", resp.Data["openai_beta"])
+	assert.NotContains(t, fmt.Sprint(resp.Data), "secret", "sensitive header values must not be returned")
+
+	// Reserved headers are rejected and the stored config is left unchanged.
+	resp = write(map[string]interface{}{
+		"custom_headers": map[string]interface{}{"Authorization": "Bearer other"},
+	})
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "reserved")
+
+	config, err = getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Team": "ml"}, config.CustomHeaders)
+}
@@ -33,69 +33,89 @@ func existenceCheckForNamedPath(fieldName string, pathGenerator func(string) str
 	}
 }
 
-// configureClientFromStorage creates and configures a client from storage configuration
+// configureClientFromStorage creates and configures a client from the named
+// connection's stored configuration.
 // This centralizes the repeated pattern of getting config and setting up a client
-func (b *backend) configureClientFromStorage(ctx context.Context, storage logical.Storage) (*Client, error) {
-	config, err := getConfig(ctx, storage)
+func (b *backend) configureClientFromStorage(ctx context.Context, storage logical.Storage, name string) (ClientAPI, error) {
+	config, err := getConnectionConfig(ctx, storage, name)
 	if err != nil {
 		return nil, fmt.Errorf("error getting OpenAI configuration: %w", err)
 	}
 	if config == nil {
+		if name != defaultConnectionName {
+			return nil, fmt.Errorf("OpenAI connection %q is not configured", name)
+		}
 		return nil, fmt.Errorf("OpenAI is not configured")
 	}
 
-	client := NewClient(config.AdminAPIKey, b.Logger())
-	clientConfig := &Config{
-		AdminAPIKey:    config.AdminAPIKey,
-		AdminAPIKeyID:  config.AdminAPIKeyID,
-		APIEndpoint:    config.APIEndpoint,
-		OrganizationID: config.OrganizationID,
-	}
-
-	if err := client.SetConfig(clientConfig); err != nil {
+	client, err := b.newClient(config.clientConfig(name), b.Logger())
+	if err != nil {
 		return nil, fmt.Errorf("error configuring OpenAI client: %w", err)
 	}
 
 	return client, nil
 }
 
-// ensureClientConfigured ensures the backend has a configured client.
+// getClient returns the cached client for the named connection, or nil.
+func (b *backend) getClient(name string) ClientAPI {
+	b.RLock()
+	defer b.RUnlock()
+	return b.clients[name]
+}
+
+// setClient replaces the cached client for the named connection. A nil client
+// drops the cache entry so the next request reloads it from storage.
+func (b *backend) setClient(name string, client ClientAPI) {
+	b.Lock()
+	defer b.Unlock()
+	b.setClientLocked(name, client)
+}
+
+// setClientLocked is setClient for callers that already hold the write lock.
+func (b *backend) setClientLocked(name string, client ClientAPI) {
+	if client == nil {
+		delete(b.clients, name)
+		return
+	}
+	if b.clients == nil {
+		b.clients = make(map[string]ClientAPI)
+	}
+	b.clients[name] = client
+}
+
+// ensureClientConfigured ensures the backend has a configured client for the
+// named connection.
 // It uses double-checked locking so only one goroutine initializes the client
 // when it is nil, while concurrent callers read it safely.
-func (b *backend) ensureClientConfigured(ctx context.Context, storage logical.Storage) error {
+func (b *backend) ensureClientConfigured(ctx context.Context, storage logical.Storage, name string) error {
 	// Fast path: client already set.
-	b.RLock()
-	hasClient := b.client != nil
-	b.RUnlock()
-	if hasClient {
+	if b.getClient(name) != nil {
 		return nil
 	}
 
 	// Slow path: acquire the write lock and re-check before initializing.
 	b.Lock()
 	defer b.Unlock()
-	if b.client != nil {
+	if b.clients[name] != nil {
 		return nil
 	}
-	client, err := b.configureClientFromStorage(ctx, storage)
+	client, err := b.configureClientFromStorage(ctx, storage, name)
 	if err != nil {
 		return err
 	}
-	b.client = client
+	b.setClientLocked(name, client)
 	return nil
 }
 
-// configuredClient returns a stable client snapshot or an explicit error if
-// configuration was deleted between the lazy-initialization check and the read
-// lock snapshot.
-func (b *backend) configuredClient(ctx context.Context, storage logical.Storage) (ClientAPI, error) {
-	if err := b.ensureClientConfigured(ctx, storage); err != nil {
+// configuredClient returns a stable client snapshot for the named connection
+// or an explicit error if configuration was deleted between the
+// lazy-initialization check and the read lock snapshot.
+func (b *backend) configuredClient(ctx context.Context, storage logical.Storage, name string) (ClientAPI, error) {
+	if err := b.ensureClientConfigured(ctx, storage, name); err != nil {
 		return nil, err
 	}
 
-	b.RLock()
-	client := b.client
-	b.RUnlock()
+	client := b.getClient(name)
 	if client == nil {
 		return nil, fmt.Errorf("OpenAI is not configured")
 	}
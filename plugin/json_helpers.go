@@ -84,3 +84,12 @@ func UnixTimePtr(t *time.Time) *UnixTime {
 	ut := UnixTime(*t)
 	return &ut
 }
+
+// formatUnixTimestamp formats a timestamp from an OpenAI response as RFC 3339.
+// Missing, zero or Unix epoch timestamps yield an empty string.
+func formatUnixTimestamp(t *UnixTime) string {
+	if t == nil || t.Time().IsZero() || t.Time().Unix() == 0 {
+		return ""
+	}
+	return t.Time().UTC().Format(time.RFC3339)
+}
@@ -10,6 +10,8 @@ import (
 	"net/http/httptest"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,22 +22,64 @@ type MockOpenAIServer struct {
 	server            *httptest.Server
 	serviceAccounts   map[string]map[string]*ServiceAccount // map[projectID]map[svcAccID]*ServiceAccount
 	apiKeys           map[string]*APIKey                    // map[apiKeyID]*APIKey
+	adminKeys         map[string]*adminAPIKey               // map[adminKeyID]*adminAPIKey
 	mutex             sync.RWMutex
-	failureMode       string // can be "create_svc_acc", "create_key", "delete_svc_acc", "delete_key"
+	failureMode       string // can be "create_svc_acc", "create_key", "delete_svc_acc", "delete_key", "create_admin_key", "revoke_admin_key"
 	failureStatusCode int
 	failureMessage    string
 }
 
-// NewMockOpenAIServer creates a new instance of the mock OpenAI server
+// NewMockOpenAIServer creates a new instance of the mock OpenAI server.
+// The server starts with one admin API key, TestAdminAPIKeyID, whose value is
+// TestAPIKey, matching the credentials the tests configure.
 func NewMockOpenAIServer() *MockOpenAIServer {
 	m := &MockOpenAIServer{
 		serviceAccounts: make(map[string]map[string]*ServiceAccount),
 		apiKeys:         make(map[string]*APIKey),
+		adminKeys:       make(map[string]*adminAPIKey),
 	}
+	m.AddAdminAPIKey(TestAdminAPIKeyID, "sample-admin-key", TestAPIKey)
 	m.server = httptest.NewServer(http.HandlerFunc(m.handler))
 	return m
 }
 
+// AddAdminAPIKey seeds an existing admin API key into the mock organization
+func (m *MockOpenAIServer) AddAdminAPIKey(id, name, value string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.adminKeys[id] = newMockAdminAPIKey(id, name, value)
+}
+
+// AddServiceAccount seeds an existing service account into a mock project
+func (m *MockOpenAIServer) AddServiceAccount(projectID, id, name string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.serviceAccounts[projectID] == nil {
+		m.serviceAccounts[projectID] = make(map[string]*ServiceAccount)
+	}
+	m.serviceAccounts[projectID][id] = &ServiceAccount{ID: id, Name: name, ProjectID: projectID}
+}
+
+// HasAdminAPIKey reports whether the admin API key is still active
+func (m *MockOpenAIServer) HasAdminAPIKey(id string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	_, ok := m.adminKeys[id]
+	return ok
+}
+
+// AdminAPIKeyIDs returns the IDs of the active admin API keys
+func (m *MockOpenAIServer) AdminAPIKeyIDs() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	ids := make([]string, 0, len(m.adminKeys))
+	for id := range m.adminKeys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // URL returns the base URL of the mock server
 func (m *MockOpenAIServer) URL() string {
 	return m.server.URL
@@ -104,6 +148,22 @@ func (m *MockOpenAIServer) handler(w http.ResponseWriter, r *http.Request) {
 	// Only supporting the correct OpenAI API paths with required /organization prefix
 	serviceAccountsPattern := regexp.MustCompile(`/v1/organization/projects/([^/]+)/service_accounts(?:/([^/]+))?`)
 	adminAPIKeysPattern := regexp.MustCompile(`/v1/organization/admin_api_keys(?:/([^/]+))?`)
+	projectPattern := regexp.MustCompile(`^/v1/organization/projects/([^/]+)$`)
+
+	if matches := projectPattern.FindStringSubmatch(r.URL.Path); matches != nil {
+		if r.Method != http.MethodGet {
+			m.writeMethodNotAllowed(w)
+			return
+		}
+		// Every project exists and is active.
+		m.writeJSONResponse(w, map[string]interface{}{
+			"object": "organization.project",
+			"id":     matches[1],
+			"name":   "Test Project",
+			"status": "active",
+		})
+		return
+	}
 
 	if matches := serviceAccountsPattern.FindStringSubmatch(r.URL.Path); matches != nil {
 		projectID := matches[1]
@@ -141,7 +201,11 @@ func (m *MockOpenAIServer) handler(w http.ResponseWriter, r *http.Request) {
 
 		switch r.Method {
 		case http.MethodGet:
-			m.listAdminAPIKeys(w, r)
+			if keyID == "" {
+				m.listAdminAPIKeys(w, r)
+			} else {
+				m.getAdminAPIKey(w, r, keyID)
+			}
 		case http.MethodPost:
 			m.createAdminAPIKey(w, r)
 		case http.MethodDelete:
@@ -260,26 +324,57 @@ func (m *MockOpenAIServer) getServiceAccount(w http.ResponseWriter, _ *http.Requ
 }
 
 // listServiceAccounts handles service account listing requests
-func (m *MockOpenAIServer) listServiceAccounts(w http.ResponseWriter, _ *http.Request, projectID string) {
+func (m *MockOpenAIServer) listServiceAccounts(w http.ResponseWriter, r *http.Request, projectID string) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	// Check if project exists
-	projectAccounts, exists := m.serviceAccounts[projectID]
-	if !exists {
-		// Return empty list for non-existent projects
-		m.writeJSONResponse(w, map[string][]ServiceAccount{"data": {}})
-		return
-	}
-
-	// Collect all service accounts for the project
+	// Non-existent projects have no service accounts
+	projectAccounts := m.serviceAccounts[projectID]
 	accounts := make([]ServiceAccount, 0, len(projectAccounts))
 	for _, acc := range projectAccounts {
 		accounts = append(accounts, *acc)
 	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+
+	page, ok := mockListPage(w, r, accounts, func(acc ServiceAccount) string { return acc.ID })
+	if ok {
+		m.writeJSONResponse(w, page)
+	}
+}
 
-	// Return the list of service accounts
-	m.writeJSONResponse(w, map[string][]ServiceAccount{"data": accounts})
+// mockListPage returns the page of items, sorted by ID, that the limit and
+// after query parameters of r select, in the envelope of the OpenAI list
+// endpoints. It writes a 400 response and returns false for an invalid limit.
+func mockListPage[T any](w http.ResponseWriter, r *http.Request, items []T, id func(T) string) (map[string]interface{}, bool) {
+	limit := 20
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 100 {
+			writeError(w, http.StatusBadRequest, "invalid_request_error", "limit must be between 1 and 100")
+			return nil, false
+		}
+		limit = parsed
+	}
+
+	start := 0
+	if after := r.URL.Query().Get("after"); after != "" {
+		start = sort.Search(len(items), func(i int) bool { return id(items[i]) > after })
+	}
+	end := min(start+limit, len(items))
+	data := items[start:end]
+
+	page := map[string]interface{}{
+		"object":   "list",
+		"data":     data,
+		"has_more": end < len(items),
+		"first_id": nil,
+		"last_id":  nil,
+	}
+	if len(data) > 0 {
+		page["first_id"] = id(data[0])
+		page["last_id"] = id(data[len(data)-1])
+	}
+	return page, true
 }
 
 // deleteServiceAccount handles service account deletion requests
@@ -344,6 +439,11 @@ func (m *MockOpenAIServer) createAdminAPIKey(w http.ResponseWriter, r *http.Requ
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	if m.failureMode == "create_admin_key" {
+		writeError(w, m.failureStatusCode, "server_error", m.failureMessage)
+		return
+	}
+
 	var req map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_body", "Invalid request body")
@@ -356,16 +456,27 @@ func (m *MockOpenAIServer) createAdminAPIKey(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	now := time.Now()
-	nowUnix := now.Unix()
+	key := newMockAdminAPIKey(
+		fmt.Sprintf("key_%s", generateRandomID(10)),
+		name,
+		fmt.Sprintf("sk-adminkey%s", generateRandomID(24)))
+	m.adminKeys[key.ID] = key
+
+	// Return the created admin API key; only creation discloses the value
+	m.writeJSONResponse(w, key)
+}
+
+// newMockAdminAPIKey builds an admin API key the way OpenAI reports it
+func newMockAdminAPIKey(id, name, value string) *adminAPIKey {
+	nowUnix := time.Now().Unix()
 	key := &adminAPIKey{
 		Object:        "organization.admin_api_key",
-		ID:            fmt.Sprintf("key_%s", generateRandomID(10)),
-		Value:         fmt.Sprintf("sk-adminkey%s", generateRandomID(24)),
+		ID:            id,
+		Value:         value,
 		Name:          name,
 		CreatedAt:     nowUnix,
 		LastUsedAt:    nowUnix,
-		RedactedValue: "sk-admin...xyz",
+		RedactedValue: redactMockKey(value),
 	}
 
 	// Set owner data
@@ -375,48 +486,68 @@ func (m *MockOpenAIServer) createAdminAPIKey(w http.ResponseWriter, r *http.Requ
 	key.Owner.Name = "Test User"
 	key.Owner.CreatedAt = nowUnix
 	key.Owner.Role = "owner"
+	return key
+}
 
-	// Return the created admin API key
-	m.writeJSONResponse(w, key)
+// redactMockKey mimics OpenAI's redacted_value: the key's leading characters,
+// an ellipsis, and its last few characters.
+func redactMockKey(value string) string {
+	if len(value) <= 8 {
+		return value[:len(value)/2] + "..." + value[len(value)-2:]
+	}
+	return value[:8] + "..." + value[len(value)-3:]
+}
+
+// redacted returns the admin API key as list and retrieve operations report
+// it, without the secret value.
+func (k adminAPIKey) redacted() adminAPIKey {
+	k.Value = ""
+	return k
 }
 
 // listAdminAPIKeys handles admin API key listing requests
-func (m *MockOpenAIServer) listAdminAPIKeys(w http.ResponseWriter, _ *http.Request) {
+func (m *MockOpenAIServer) listAdminAPIKeys(w http.ResponseWriter, r *http.Request) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	// Return a sample list of admin API keys
-	now := time.Now()
-	nowUnix := now.Unix()
-	keys := []adminAPIKey{
-		{
-			Object:        "organization.admin_api_key",
-			ID:            "key_sample",
-			Name:          "sample-admin-key",
-			CreatedAt:     nowUnix,
-			LastUsedAt:    nowUnix,
-			RedactedValue: "sk-admin...xyz",
-		},
+	keys := make([]adminAPIKey, 0, len(m.adminKeys))
+	for _, key := range m.adminKeys {
+		keys = append(keys, key.redacted())
 	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
 
-	// Set owner data for the sample key
-	keys[0].Owner.Type = "user"
-	keys[0].Owner.Object = "organization.user"
-	keys[0].Owner.ID = "user_123"
-	keys[0].Owner.Name = "Test User"
-	keys[0].Owner.CreatedAt = nowUnix
-	keys[0].Owner.Role = "owner"
+	page, ok := mockListPage(w, r, keys, func(key adminAPIKey) string { return key.ID })
+	if ok {
+		m.writeJSONResponse(w, page)
+	}
+}
 
-	m.writeJSONResponse(w, map[string][]adminAPIKey{"data": keys})
+// getAdminAPIKey handles admin API key retrieval requests
+func (m *MockOpenAIServer) getAdminAPIKey(w http.ResponseWriter, _ *http.Request, keyID string) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	key, exists := m.adminKeys[keyID]
+	if !exists {
+		m.writeNotFound(w, "Admin API key not found")
+		return
+	}
+	m.writeJSONResponse(w, key.redacted())
 }
 
 // revokeAdminAPIKey handles admin API key revocation requests
-func (m *MockOpenAIServer) revokeAdminAPIKey(w http.ResponseWriter, _ *http.Request, _ string) {
+func (m *MockOpenAIServer) revokeAdminAPIKey(w http.ResponseWriter, _ *http.Request, keyID string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	// In real implementation, we would check if key exists and delete it
-	// For mock, we'll just return success
+	if m.failureMode == "revoke_admin_key" {
+		writeError(w, m.failureStatusCode, "server_error", m.failureMessage)
+		return
+	}
+
+	// Revoking an unknown key succeeds so tests that configure arbitrary key
+	// IDs can still rotate.
+	delete(m.adminKeys, keyID)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -0,0 +1,195 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// DefaultListPageSize is the page size used when ListOptions.Limit is
+	// zero. It is the largest page the OpenAI list endpoints return.
+	DefaultListPageSize = 100
+
+	maxListPageSize = 100
+)
+
+// ListOptions selects a page of an OpenAI list endpoint.
+type ListOptions struct {
+	// Limit is the number of objects per page, between 1 and 100. Zero uses
+	// DefaultListPageSize.
+	Limit int
+
+	// After is the cursor: the ID of the last object of the previous page.
+	// Empty starts at the first page.
+	After string
+}
+
+// query returns the URL query for opts.
+func (opts ListOptions) query() (string, error) {
+	limit := opts.Limit
+	if limit == 0 {
+		limit = DefaultListPageSize
+	}
+	if limit < 1 || limit > maxListPageSize {
+		return "", fmt.Errorf("list limit must be between 1 and %d", maxListPageSize)
+	}
+
+	values := url.Values{}
+	values.Set("limit", strconv.Itoa(limit))
+	if opts.After != "" {
+		values.Set("after", opts.After)
+	}
+	return "?" + values.Encode(), nil
+}
+
+// Page is one page of an OpenAI list endpoint. When HasMore is set, passing
+// LastID as ListOptions.After fetches the next page.
+type Page[T any] struct {
+	Data    []T
+	FirstID string
+	LastID  string
+	HasMore bool
+}
+
+// listResponse is the envelope of the OpenAI list endpoints.
+type listResponse[T any] struct {
+	Data    []T    `json:"data"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+	HasMore bool   `json:"has_more"`
+}
+
+// getPage fetches one page of the list endpoint at path. id returns the ID
+// of an object, used as the cursor when the response omits last_id.
+func getPage[T any](ctx context.Context, c *Client, path string, opts ListOptions, id func(T) string) (*Page[T], error) {
+	query, err := opts.query()
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := c.doRequest(ctx, http.MethodGet, path+query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result listResponse[T]
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("error parsing list response: %w", err)
+	}
+
+	page := &Page[T]{
+		Data:    result.Data,
+		FirstID: result.FirstID,
+		LastID:  result.LastID,
+		HasMore: result.HasMore,
+	}
+	if len(page.Data) > 0 {
+		if page.FirstID == "" {
+			page.FirstID = id(page.Data[0])
+		}
+		if page.LastID == "" {
+			page.LastID = id(page.Data[len(page.Data)-1])
+		}
+	}
+	return page, nil
+}
+
+// paginate iterates over every object from opts onwards, fetching pages as
+// needed. Iteration stops at the first error, which is yielded with a zero
+// object.
+func paginate[T any](opts ListOptions, fetch func(ListOptions) (*Page[T], error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for {
+			page, err := fetch(opts)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Data {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if !page.HasMore {
+				return
+			}
+			// A page that reports more objects but does not advance the
+			// cursor would otherwise be requested forever.
+			if page.LastID == "" || page.LastID == opts.After {
+				yield(zero, fmt.Errorf("list response has more objects but no new cursor after %q", opts.After))
+				return
+			}
+			opts.After = page.LastID
+		}
+	}
+}
+
+// collect returns every object yielded by seq, or the first error.
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	items := []T{}
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ListServiceAccountsPage returns one page of the service accounts of a
+// project.
+func (c *Client) ListServiceAccountsPage(ctx context.Context, projectID string, opts ListOptions) (*Page[*ServiceAccount], error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("project ID is required")
+	}
+	page, err := getPage(ctx, c, fmt.Sprintf(serviceAccountsEndpointFmt, projectID), opts,
+		func(sa *ServiceAccount) string {
+			if sa == nil {
+				return ""
+			}
+			return sa.ID
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error listing service accounts: %w", err)
+	}
+	return page, nil
+}
+
+// ServiceAccounts iterates over the service accounts of a project from opts
+// onwards, fetching further pages as needed.
+func (c *Client) ServiceAccounts(ctx context.Context, projectID string, opts ListOptions) iter.Seq2[*ServiceAccount, error] {
+	return paginate(opts, func(opts ListOptions) (*Page[*ServiceAccount], error) {
+		return c.ListServiceAccountsPage(ctx, projectID, opts)
+	})
+}
+
+// ListAdminAPIKeysPage returns one page of the organization's admin API keys.
+func (c *Client) ListAdminAPIKeysPage(ctx context.Context, opts ListOptions) (*Page[*AdminAPIKey], error) {
+	page, err := getPage(ctx, c, adminAPIKeysEndpoint, opts,
+		func(key *AdminAPIKey) string {
+			if key == nil {
+				return ""
+			}
+			return key.ID
+		})
+	if err != nil {
+		return nil, fmt.Errorf("error listing admin API keys: %w", err)
+	}
+	return page, nil
+}
+
+// AdminAPIKeys iterates over the organization's admin API keys from opts
+// onwards, fetching further pages as needed.
+func (c *Client) AdminAPIKeys(ctx context.Context, opts ListOptions) iter.Seq2[*AdminAPIKey, error] {
+	return paginate(opts, func(opts ListOptions) (*Page[*AdminAPIKey], error) {
+		return c.ListAdminAPIKeysPage(ctx, opts)
+	})
+}
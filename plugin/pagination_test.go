@@ -0,0 +1,168 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// paginationTestClient returns a client for the mock server with retries
+// disabled.
+func paginationTestClient(t *testing.T, url string) *Client {
+	client := NewClient(TestAPIKey, hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:          TestAPIKey,
+		APIEndpoint:          url,
+		OrganizationID:       TestOrganizationID,
+		RequestRetryAttempts: 1,
+	}))
+	return client
+}
+
+func TestListOptions_Query(t *testing.T) {
+	query, err := ListOptions{}.query()
+	require.NoError(t, err)
+	assert.Equal(t, "?limit=100", query)
+
+	query, err = ListOptions{Limit: 5, After: "svc_a&b"}.query()
+	require.NoError(t, err)
+	assert.Equal(t, "?after=svc_a%26b&limit=5", query)
+
+	_, err = ListOptions{Limit: 101}.query()
+	require.Error(t, err)
+	_, err = ListOptions{Limit: -1}.query()
+	require.Error(t, err)
+}
+
+func TestClient_ListServiceAccountsPages(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+	for i := range 5 {
+		mockServer.AddServiceAccount(TestProjectID, fmt.Sprintf("svc_%d", i), fmt.Sprintf("account-%d", i))
+	}
+	client := paginationTestClient(t, mockServer.URL()+"/v1")
+	ctx := context.Background()
+
+	page, err := client.ListServiceAccountsPage(ctx, TestProjectID, ListOptions{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Data, 2)
+	assert.Equal(t, "svc_0", page.FirstID)
+	assert.Equal(t, "svc_1", page.LastID)
+	assert.True(t, page.HasMore)
+
+	page, err = client.ListServiceAccountsPage(ctx, TestProjectID, ListOptions{Limit: 2, After: page.LastID})
+	require.NoError(t, err)
+	assert.Equal(t, "svc_2", page.Data[0].ID)
+	assert.True(t, page.HasMore)
+
+	page, err = client.ListServiceAccountsPage(ctx, TestProjectID, ListOptions{Limit: 2, After: "svc_3"})
+	require.NoError(t, err)
+	require.Len(t, page.Data, 1)
+	assert.False(t, page.HasMore)
+
+	// The iterator follows the cursor across pages.
+	var ids []string
+	for account, err := range client.ServiceAccounts(ctx, TestProjectID, ListOptions{Limit: 2}) {
+		require.NoError(t, err)
+		ids = append(ids, account.ID)
+	}
+	assert.Equal(t, []string{"svc_0", "svc_1", "svc_2", "svc_3", "svc_4"}, ids)
+
+	// ListServiceAccounts is no longer limited to the first page.
+	for i := 5; i < 150; i++ {
+		mockServer.AddServiceAccount(TestProjectID, fmt.Sprintf("svc_%03d", i), "bulk")
+	}
+	accounts, err := client.ListServiceAccounts(ctx, TestProjectID)
+	require.NoError(t, err)
+	assert.Len(t, accounts, 150)
+}
+
+func TestClient_ServiceAccountsIteratorStopsEarly(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"object": "list", "data": [{"id": "svc_1"}, {"id": "svc_2"}], "has_more": true, "last_id": "svc_2"}`))
+	}))
+	defer server.Close()
+	client := paginationTestClient(t, server.URL)
+
+	for account, err := range client.ServiceAccounts(context.Background(), TestProjectID, ListOptions{}) {
+		require.NoError(t, err)
+		assert.Equal(t, "svc_1", account.ID)
+		break
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "no further page is fetched after the caller stops")
+}
+
+func TestClient_PaginationStuckCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"object": "list", "data": [{"id": "key_1"}], "has_more": true, "last_id": "key_1"}`))
+	}))
+	defer server.Close()
+	client := paginationTestClient(t, server.URL)
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no new cursor")
+}
+
+func TestClient_PaginationWithoutLastID(t *testing.T) {
+	var afters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		after := r.URL.Query().Get("after")
+		afters = append(afters, after)
+		if after == "" {
+			_, _ = w.Write([]byte(`{"object": "list", "data": [{"id": "key_1"}], "has_more": true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"object": "list", "data": [{"id": "key_2"}], "has_more": false}`))
+	}))
+	defer server.Close()
+	client := paginationTestClient(t, server.URL)
+
+	keys, err := client.ListAdminAPIKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, []string{"", "key_1"}, afters, "the last object's ID is used as the cursor")
+}
+
+func TestClient_AdminAPIKeysPages(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+	for i := range 120 {
+		mockServer.AddAdminAPIKey(fmt.Sprintf("key_%03d", i), "extra", fmt.Sprintf("sk-admin-extra-%03d", i))
+	}
+	client := paginationTestClient(t, mockServer.URL()+"/v1")
+	ctx := context.Background()
+
+	page, err := client.ListAdminAPIKeysPage(ctx, ListOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Data, 10)
+	assert.True(t, page.HasMore)
+
+	var count int
+	for _, err := range client.AdminAPIKeys(ctx, ListOptions{Limit: 7}) {
+		require.NoError(t, err)
+		count++
+	}
+	assert.Equal(t, 121, count)
+
+	keys, err := client.ListAdminAPIKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 121)
+
+	// The configured key sorts last, beyond the first page.
+	keyID, err := client.DiscoverAdminAPIKeyID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, TestAdminAPIKeyID, keyID)
+}
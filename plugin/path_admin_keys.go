@@ -0,0 +1,508 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/mitchellh/mapstructure"
+)
+
+// vaultAdminKeyNamePrefix is the name prefix of admin keys created by
+// rotation. It identifies keys that Vault created in the OpenAI organization.
+const vaultAdminKeyNamePrefix = "vault-admin-key-"
+
+// adminKeyEntry is an organization admin key classified against the configs
+// of every connection to the organization.
+type adminKeyEntry struct {
+	ID                 string
+	Name               string
+	RedactedValue      string
+	CreatedAt          string
+	LastUsedAt         string
+	Current            bool
+	InUseBy            string
+	VaultCreated       bool
+	PendingRevocation  bool
+	RotationInProgress bool
+}
+
+// straggler reports whether the key was created by Vault but is neither in
+// use by any connection, awaiting deferred revocation, nor being created by a
+// rotation.
+func (k adminKeyEntry) straggler() bool {
+	return k.VaultCreated && !k.Current && k.InUseBy == "" && !k.PendingRevocation && !k.RotationInProgress
+}
+
+// organizationAdminKeys records the admin keys that the connections to an
+// OpenAI organization depend on.
+type organizationAdminKeys struct {
+	// current maps each connection's current key ID to the connection.
+	current map[string]string
+	// retired holds the keys awaiting deferred revocation.
+	retired map[string]bool
+	// rotatingIDs and rotatingNames identify keys created by rotations that
+	// have not completed.
+	rotatingIDs   map[string]bool
+	rotatingNames map[string]bool
+}
+
+// pathAdminKeys returns the paths for inspecting and cleaning up the admin
+// keys of the OpenAI organization behind a connection.
+func (b *backend) pathAdminKeys() []*framework.Path {
+	connectionField := &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Name of the connection whose organization admin keys are managed. Defaults to the connection stored at config.",
+		Default:     defaultConnectionName,
+	}
+
+	return []*framework.Path{
+		{
+			Pattern: "admin-keys/cleanup",
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "openai",
+				OperationVerb:   "clean-up",
+				OperationSuffix: "admin-keys",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				"connection": connectionField,
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: "Report the keys that would be revoked without revoking them.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:                    b.pathAdminKeysCleanup,
+					ForwardPerformanceStandby:   true,
+					ForwardPerformanceSecondary: true,
+					Summary:                     "Revoke Vault-created admin keys that are no longer in use.",
+				},
+			},
+			HelpSynopsis:    adminKeysCleanupHelpSyn,
+			HelpDescription: adminKeysCleanupHelpDesc,
+		},
+		{
+			Pattern: "admin-keys/revoke-all-except-current",
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "openai",
+				OperationVerb:   "revoke",
+				OperationSuffix: "all-admin-keys-except-current",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				"connection": connectionField,
+				"confirm": {
+					Type:        framework.TypeBool,
+					Description: "Must be true. Guards against revoking every other admin key by accident.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:                    b.pathAdminKeysRevokeAll,
+					ForwardPerformanceStandby:   true,
+					ForwardPerformanceSecondary: true,
+					Summary:                     "Revoke every organization admin key except the current one.",
+				},
+			},
+			HelpSynopsis:    adminKeysRevokeAllHelpSyn,
+			HelpDescription: adminKeysRevokeAllHelpDesc,
+		},
+		{
+			Pattern: "admin-keys/?$",
+			Fields: map[string]*framework.FieldSchema{
+				"connection": connectionField,
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.pathAdminKeysList,
+					Summary:  "List the organization's admin keys.",
+				},
+			},
+			HelpSynopsis:    adminKeysListHelpSyn,
+			HelpDescription: adminKeysListHelpDesc,
+		},
+	}
+}
+
+// pathAdminKeysList lists every admin key in the connection's organization,
+// flagging Vault-created keys that are no longer in use.
+func (b *backend) pathAdminKeysList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := adminKeysConnection(data)
+
+	config, client, err := b.adminKeysClient(ctx, req.Storage, name)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	keys, err := b.listAdminKeyEntries(ctx, req.Storage, client, name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(keys))
+	keyInfo := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+		keyInfo[key.ID] = map[string]interface{}{
+			"name":                 key.Name,
+			"redacted_value":       key.RedactedValue,
+			"created_at":           key.CreatedAt,
+			"last_used_at":         key.LastUsedAt,
+			"current":              key.Current,
+			"in_use_by":            key.InUseBy,
+			"vault_created":        key.VaultCreated,
+			"pending_revocation":   key.PendingRevocation,
+			"rotation_in_progress": key.RotationInProgress,
+			"straggler":            key.straggler(),
+		}
+	}
+
+	return logical.ListResponseWithInfo(ids, keyInfo), nil
+}
+
+// pathAdminKeysCleanup revokes Vault-created admin keys that no connection to
+// the organization uses or awaits revoking.
+func (b *backend) pathAdminKeysCleanup(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := adminKeysConnection(data)
+
+	// Hold the connection's config lock so a key being created by an
+	// in-flight rotation is not mistaken for a straggler.
+	lock := b.configLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	config, client, err := b.adminKeysClient(ctx, req.Storage, name)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	keys, err := b.listAdminKeyEntries(ctx, req.Storage, client, name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	for _, key := range keys {
+		if key.straggler() {
+			targets = append(targets, key.ID)
+		}
+	}
+
+	if data.Get("dry_run").(bool) {
+		return &logical.Response{Data: map[string]interface{}{
+			"connection":   name,
+			"dry_run":      true,
+			"would_revoke": nonNilStrings(targets),
+		}}, nil
+	}
+
+	revoked, failed := b.revokeAdminKeys(ctx, client, name, targets)
+	return adminKeysRevocationResponse(name, revoked, failed), nil
+}
+
+// pathAdminKeysRevokeAll revokes every admin key in the organization except
+// the current keys of the connections to it and keys being created by
+// rotation. It is meant for incident response, when any other admin key may be
+// compromised.
+func (b *backend) pathAdminKeysRevokeAll(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := adminKeysConnection(data)
+	if !data.Get("confirm").(bool) {
+		return logical.ErrorResponse("confirm must be true to revoke every admin key except the current one"), nil
+	}
+
+	lock := b.configLock(name)
+	lock.Lock()
+	config, client, err := b.adminKeysClient(ctx, req.Storage, name)
+	if err != nil {
+		lock.Unlock()
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	keys, err := b.listAdminKeyEntries(ctx, req.Storage, client, name, config)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+
+	var targets []string
+	for _, key := range keys {
+		if !key.Current && key.InUseBy == "" && !key.RotationInProgress {
+			targets = append(targets, key.ID)
+		}
+	}
+
+	revoked, failed := b.revokeAdminKeys(ctx, client, name, targets)
+	lock.Unlock()
+
+	// Revoked keys no longer need deferred revocation, whichever connection
+	// to the organization retired them. Each connection's config is updated
+	// under its own lock, taking one lock at a time.
+	if len(revoked) > 0 {
+		done := make(map[string]bool, len(revoked))
+		for _, id := range revoked {
+			done[id] = true
+		}
+		names, err := req.Storage.List(ctx, configPath+"/")
+		if err != nil {
+			return nil, fmt.Errorf("revoked admin keys but failed to list connections: %w", err)
+		}
+		for _, connection := range append([]string{defaultConnectionName}, names...) {
+			if err := b.forgetRetiredAdminKeys(ctx, req.Storage, connection, done); err != nil {
+				return nil, fmt.Errorf("revoked admin keys but failed to update retired keys of connection %q: %w", connection, err)
+			}
+		}
+	}
+
+	return adminKeysRevocationResponse(name, revoked, failed), nil
+}
+
+// forgetRetiredAdminKeys removes the revoked keys from the named
+// connection's retired keys, so deferred revocation does not retry them.
+func (b *backend) forgetRetiredAdminKeys(ctx context.Context, storage logical.Storage, name string, revoked map[string]bool) error {
+	lock := b.configLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	config, err := getConnectionConfig(ctx, storage, name)
+	if err != nil || config == nil {
+		return err
+	}
+	pending := make([]retiredAdminKey, 0, len(config.RetiredAdminKeys))
+	for _, key := range config.RetiredAdminKeys {
+		if !revoked[key.ID] {
+			pending = append(pending, key)
+		}
+	}
+	if len(pending) == len(config.RetiredAdminKeys) {
+		return nil
+	}
+	if len(pending) == 0 {
+		pending = nil
+	}
+	config.RetiredAdminKeys = pending
+	return putConnectionConfig(ctx, storage, name, config)
+}
+
+// adminKeysConnection returns the connection named in the request.
+func adminKeysConnection(data *framework.FieldData) string {
+	if name := data.Get("connection").(string); name != "" {
+		return name
+	}
+	return defaultConnectionName
+}
+
+// adminKeysClient returns the connection's config and a client for it.
+func (b *backend) adminKeysClient(ctx context.Context, storage logical.Storage, name string) (*openaiConfig, ClientAPI, error) {
+	config, err := getConnectionConfig(ctx, storage, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config == nil {
+		return nil, nil, fmt.Errorf("connection %q is not configured", name)
+	}
+	if config.AdminAPIKeyID == "" {
+		return nil, nil, fmt.Errorf("connection %q has no admin_api_key_id; the current key cannot be identified", name)
+	}
+
+	client, err := b.configureClientFromStorage(ctx, storage, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, client, nil
+}
+
+// listAdminKeyEntries lists the organization's admin keys and classifies them
+// against the config of the named connection and of every other connection
+// to the same organization.
+func (b *backend) listAdminKeyEntries(ctx context.Context, storage logical.Storage, client ClientAPI, name string, config *openaiConfig) ([]adminKeyEntry, error) {
+	keys, err := client.ListAdminAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read after listing: a rotation saves its new key in config before it
+	// deletes its WAL entry, so every listed key is accounted for.
+	org, err := b.organizationAdminKeys(ctx, storage, config.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range config.RetiredAdminKeys {
+		org.retired[key.ID] = true
+	}
+
+	entries := make([]adminKeyEntry, 0, len(keys))
+	for _, key := range keys {
+		if key == nil || key.ID == "" {
+			continue
+		}
+		current := key.ID == config.AdminAPIKeyID
+		entry := adminKeyEntry{
+			ID:                 key.ID,
+			Name:               key.Name,
+			RedactedValue:      key.RedactedValue,
+			CreatedAt:          formatUnixTimestamp(key.CreatedAt),
+			LastUsedAt:         formatUnixTimestamp(key.LastUsedAt),
+			Current:            current,
+			VaultCreated:       strings.HasPrefix(key.Name, vaultAdminKeyNamePrefix),
+			PendingRevocation:  org.retired[key.ID],
+			RotationInProgress: org.rotatingIDs[key.ID] || org.rotatingNames[key.Name],
+		}
+		if connection, ok := org.current[key.ID]; ok && !current && connection != name {
+			entry.InUseBy = connection
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// organizationAdminKeys collects the admin keys that connections to the
+// organization depend on: the current and retired keys of every connection
+// with the organization ID, and the keys of rotations that have not completed.
+// WAL entries are read before configs so a rotation that completes in between
+// is still seen in one of them.
+func (b *backend) organizationAdminKeys(ctx context.Context, storage logical.Storage, organizationID string) (*organizationAdminKeys, error) {
+	org := &organizationAdminKeys{
+		current:       map[string]string{},
+		retired:       map[string]bool{},
+		rotatingIDs:   map[string]bool{},
+		rotatingNames: map[string]bool{},
+	}
+
+	walIDs, err := framework.ListWAL(ctx, storage)
+	if err != nil {
+		return nil, fmt.Errorf("error listing WAL entries: %w", err)
+	}
+	var rotations []adminKeyRotationWAL
+	for _, id := range walIDs {
+		wal, err := framework.GetWAL(ctx, storage, id)
+		if err != nil {
+			return nil, fmt.Errorf("error reading WAL entry: %w", err)
+		}
+		if wal == nil || wal.Kind != adminKeyRotationWALKind {
+			continue
+		}
+		var rotation adminKeyRotationWAL
+		if err := mapstructure.Decode(wal.Data, &rotation); err != nil {
+			return nil, fmt.Errorf("error decoding WAL entry: %w", err)
+		}
+		rotations = append(rotations, rotation)
+	}
+
+	names, err := storage.List(ctx, configPath+"/")
+	if err != nil {
+		return nil, err
+	}
+	names = append([]string{defaultConnectionName}, names...)
+
+	sameOrg := map[string]bool{}
+	for _, name := range names {
+		config, err := getConnectionConfig(ctx, storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil || config.OrganizationID != organizationID {
+			continue
+		}
+		sameOrg[name] = true
+		if config.AdminAPIKeyID != "" {
+			org.current[config.AdminAPIKeyID] = name
+		}
+		for _, key := range config.RetiredAdminKeys {
+			org.retired[key.ID] = true
+		}
+	}
+
+	for _, rotation := range rotations {
+		if !sameOrg[rotation.Connection] {
+			continue
+		}
+		if rotation.KeyID != "" {
+			org.rotatingIDs[rotation.KeyID] = true
+		}
+		if rotation.KeyName != "" {
+			org.rotatingNames[rotation.KeyName] = true
+		}
+	}
+	return org, nil
+}
+
+// revokeAdminKeys revokes each key, continuing past failures.
+func (b *backend) revokeAdminKeys(ctx context.Context, client ClientAPI, connection string, ids []string) ([]string, map[string]string) {
+	revoked := []string{}
+	failed := map[string]string{}
+	for _, id := range ids {
+		if err := client.RevokeAdminAPIKey(ctx, id); err != nil {
+			failed[id] = err.Error()
+			continue
+		}
+		revoked = append(revoked, id)
+	}
+	b.Logger().Info("Revoked organization admin keys", "connection", connection, "revoked", len(revoked), "failed", len(failed))
+	return revoked, failed
+}
+
+// adminKeysRevocationResponse reports the outcome of a bulk revocation.
+func adminKeysRevocationResponse(connection string, revoked []string, failed map[string]string) *logical.Response {
+	resp := &logical.Response{Data: map[string]interface{}{
+		"connection": connection,
+		"revoked":    revoked,
+	}}
+	if len(failed) > 0 {
+		resp.Data["failed"] = failed
+		resp.AddWarning(fmt.Sprintf("%d admin key(s) could not be revoked", len(failed)))
+	}
+	return resp
+}
+
+// nonNilStrings returns s, or an empty slice if s is nil, so responses
+// render an empty list rather than null.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+const adminKeysListHelpSyn = `
+List the admin keys of a connection's OpenAI organization.
+`
+
+const adminKeysListHelpDesc = `
+This endpoint lists every admin API key in the OpenAI organization behind a
+connection. Each key is flagged as "current" (the key the connection uses),
+"in_use_by" (the other connection to the organization that uses it),
+"vault_created" (named by rotation), "pending_revocation" (awaiting deferred
+revocation after rotation by any connection to the organization),
+"rotation_in_progress" (being created by a rotation) and "straggler" (created
+by Vault but none of the above, for example after a failed revocation).
+`
+
+const adminKeysCleanupHelpSyn = `
+Revoke Vault-created admin keys that are no longer in use.
+`
+
+const adminKeysCleanupHelpDesc = `
+This endpoint revokes the admin keys listed as stragglers by "admin-keys/".
+Keys that were not created by Vault, keys used by any connection to the
+organization, keys awaiting deferred revocation and keys being created by a
+rotation are left alone. Set "dry_run" to see which keys would be
+revoked.
+`
+
+const adminKeysRevokeAllHelpSyn = `
+Revoke every organization admin key except the current one.
+`
+
+const adminKeysRevokeAllHelpDesc = `
+For incident response. This endpoint revokes every admin key in the OpenAI
+organization, including keys not created by Vault, except the keys that
+connections to the organization currently use and keys being created by a
+rotation. Revoked keys are removed from the retired keys of every connection
+waiting to revoke them. Set "confirm" to true to proceed. Consider rotating
+the current key afterwards.
+`
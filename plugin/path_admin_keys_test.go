@@ -0,0 +1,180 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAdminKeysTest configures the default connection against a mock
+// organization holding the current key, a Vault-created straggler, a retired
+// key awaiting revocation and a key created outside Vault.
+func setupAdminKeysTest(t *testing.T) (*backend, logical.Storage, *MockOpenAIServer) {
+	t.Helper()
+	mockServer := NewMockOpenAIServer()
+	t.Cleanup(mockServer.Close)
+	mockServer.AddAdminAPIKey("key_stray", vaultAdminKeyNamePrefix+"stray", "sk-admin-stray-value")
+	mockServer.AddAdminAPIKey("key_retired", vaultAdminKeyNamePrefix+"retired", "sk-admin-retired-value")
+	mockServer.AddAdminAPIKey("key_human", "break-glass", "sk-admin-human-value")
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	cfg.RetiredAdminKeys = []retiredAdminKey{{ID: "key_retired", RetiredAt: time.Now(), RevokeAfter: time.Now().Add(time.Hour)}}
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, cfg))
+
+	return b, storage, mockServer
+}
+
+func adminKeysRequest(t *testing.T, b *backend, storage logical.Storage, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+	t.Helper()
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: op,
+		Path:      path,
+		Storage:   storage,
+		Data:      data,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	return resp
+}
+
+func TestAdminKeys_List(t *testing.T) {
+	b, storage, _ := setupAdminKeysTest(t)
+
+	resp := adminKeysRequest(t, b, storage, logical.ListOperation, "admin-keys/", nil)
+	require.False(t, resp.IsError(), "unexpected error: %v", resp.Error())
+	assert.ElementsMatch(t, []string{TestAdminAPIKeyID, "key_stray", "key_retired", "key_human"}, resp.Data["keys"])
+
+	info := resp.Data["key_info"].(map[string]interface{})
+	flags := func(id string) map[string]interface{} { return info[id].(map[string]interface{}) }
+
+	assert.Equal(t, true, flags(TestAdminAPIKeyID)["current"])
+	assert.Equal(t, false, flags(TestAdminAPIKeyID)["straggler"])
+	assert.Equal(t, true, flags("key_stray")["vault_created"])
+	assert.Equal(t, true, flags("key_stray")["straggler"])
+	assert.Equal(t, true, flags("key_retired")["pending_revocation"])
+	assert.Equal(t, false, flags("key_retired")["straggler"])
+	assert.Equal(t, false, flags("key_human")["vault_created"])
+	assert.Equal(t, false, flags("key_human")["straggler"])
+}
+
+func TestAdminKeys_Cleanup(t *testing.T) {
+	b, storage, mockServer := setupAdminKeysTest(t)
+
+	resp := adminKeysRequest(t, b, storage, logical.UpdateOperation, "admin-keys/cleanup", map[string]interface{}{"dry_run": true})
+	assert.Equal(t, []string{"key_stray"}, resp.Data["would_revoke"])
+	assert.True(t, mockServer.HasAdminAPIKey("key_stray"), "dry run must not revoke")
+
+	resp = adminKeysRequest(t, b, storage, logical.UpdateOperation, "admin-keys/cleanup", nil)
+	assert.Equal(t, []string{"key_stray"}, resp.Data["revoked"])
+	assert.ElementsMatch(t, []string{TestAdminAPIKeyID, "key_retired", "key_human"}, mockServer.AdminAPIKeyIDs())
+}
+
+func TestAdminKeys_RevokeAllExceptCurrent(t *testing.T) {
+	b, storage, mockServer := setupAdminKeysTest(t)
+	ctx := context.Background()
+
+	resp := adminKeysRequest(t, b, storage, logical.UpdateOperation, "admin-keys/revoke-all-except-current", nil)
+	assert.True(t, resp.IsError(), "confirm is required")
+	assert.Len(t, mockServer.AdminAPIKeyIDs(), 4)
+
+	resp = adminKeysRequest(t, b, storage, logical.UpdateOperation, "admin-keys/revoke-all-except-current",
+		map[string]interface{}{"confirm": true})
+	require.False(t, resp.IsError(), "unexpected error: %v", resp.Error())
+	assert.ElementsMatch(t, []string{"key_stray", "key_retired", "key_human"}, resp.Data["revoked"])
+	assert.Equal(t, []string{TestAdminAPIKeyID}, mockServer.AdminAPIKeyIDs())
+
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.RetiredAdminKeys, "revoked retired keys are no longer pending")
+}
+
+func TestAdminKeys_SharedOrganization(t *testing.T) {
+	b, storage, mockServer := setupAdminKeysTest(t)
+	ctx := context.Background()
+
+	// A second connection to the same organization uses a key rotated in by
+	// Vault and has a retired key of its own awaiting revocation.
+	mockServer.AddAdminAPIKey("key_other", vaultAdminKeyNamePrefix+"other", "sk-admin-other-value")
+	mockServer.AddAdminAPIKey("key_other_retired", vaultAdminKeyNamePrefix+"other-retired", "sk-admin-other-retired-value")
+	other, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	other.Version = 0
+	other.AdminAPIKeyID = "key_other"
+	other.RetiredAdminKeys = []retiredAdminKey{{ID: "key_other_retired", RetiredAt: time.Now(), RevokeAfter: time.Now().Add(time.Hour)}}
+	require.NoError(t, putConnectionConfig(ctx, storage, "analytics", other))
+
+	// A rotation of the second connection is creating a key.
+	mockServer.AddAdminAPIKey("key_rotating", vaultAdminKeyNamePrefix+"rotating", "sk-admin-rotating-value")
+	_, err = framework.PutWAL(ctx, storage, adminKeyRotationWALKind, &adminKeyRotationWAL{
+		Connection: "analytics",
+		KeyName:    vaultAdminKeyNamePrefix + "rotating",
+	})
+	require.NoError(t, err)
+
+	resp := adminKeysRequest(t, b, storage, logical.ListOperation, "admin-keys/", nil)
+	require.False(t, resp.IsError(), "unexpected error: %v", resp.Error())
+	info := resp.Data["key_info"].(map[string]interface{})
+	flags := func(id string) map[string]interface{} { return info[id].(map[string]interface{}) }
+	assert.Equal(t, false, flags("key_other")["current"])
+	assert.Equal(t, "analytics", flags("key_other")["in_use_by"])
+	assert.Equal(t, false, flags("key_other")["straggler"])
+	assert.Equal(t, true, flags("key_other_retired")["pending_revocation"])
+	assert.Equal(t, true, flags("key_rotating")["rotation_in_progress"])
+	assert.Equal(t, false, flags("key_rotating")["straggler"])
+
+	resp = adminKeysRequest(t, b, storage, logical.UpdateOperation, "admin-keys/cleanup", nil)
+	assert.Equal(t, []string{"key_stray"}, resp.Data["revoked"])
+	assert.True(t, mockServer.HasAdminAPIKey("key_other"), "the other connection's key must not be revoked")
+	assert.True(t, mockServer.HasAdminAPIKey("key_other_retired"))
+	assert.True(t, mockServer.HasAdminAPIKey("key_rotating"))
+
+	resp = adminKeysRequest(t, b, storage, logical.UpdateOperation, "admin-keys/revoke-all-except-current",
+		map[string]interface{}{"confirm": true})
+	require.False(t, resp.IsError(), "unexpected error: %v", resp.Error())
+	assert.ElementsMatch(t, []string{TestAdminAPIKeyID, "key_other", "key_rotating"}, mockServer.AdminAPIKeyIDs())
+
+	// The other connection no longer waits to revoke its retired key.
+	other, err = getConnectionConfig(ctx, storage, "analytics")
+	require.NoError(t, err)
+	assert.Empty(t, other.RetiredAdminKeys)
+	assert.Equal(t, "key_other", other.AdminAPIKeyID)
+}
+
+func TestAdminKeys_OtherOrganization(t *testing.T) {
+	b, storage, mockServer := setupAdminKeysTest(t)
+	ctx := context.Background()
+
+	// A connection to another organization does not protect keys in this one.
+	other, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	other.Version = 0
+	other.OrganizationID = "org-other"
+	other.AdminAPIKeyID = "key_stray"
+	require.NoError(t, putConnectionConfig(ctx, storage, "elsewhere", other))
+
+	resp := adminKeysRequest(t, b, storage, logical.UpdateOperation, "admin-keys/cleanup", nil)
+	assert.Equal(t, []string{"key_stray"}, resp.Data["revoked"])
+	assert.False(t, mockServer.HasAdminAPIKey("key_stray"))
+}
+
+func TestAdminKeys_UnknownConnection(t *testing.T) {
+	b := getTestBackend(t)
+
+	resp := adminKeysRequest(t, b, &logical.InmemStorage{}, logical.ListOperation, "admin-keys/",
+		map[string]interface{}{"connection": "missing"})
+	assert.True(t, resp.IsError())
+}
@@ -5,6 +5,7 @@ package openaisecrets
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -15,9 +16,17 @@ import (
 	"github.com/hashicorp/vault/sdk/rotation"
 )
 
+// errConfigConflict is returned when a connection's config changed between
+// being read and saved.
+var errConfigConflict = errors.New("OpenAI configuration was modified concurrently; retry the request")
+
 const (
 	configPath        = "config"
 	adminAPIKeyPrefix = "sk-admin"
+
+	// defaultConnectionName identifies the connection stored at "config".
+	// Roles and leases created before named connections existed use it.
+	defaultConnectionName = "default"
 )
 
 func hasExpectedAdminAPIKeyPrefix(key string) bool {
@@ -33,10 +42,135 @@ type openaiConfig struct {
 	OrganizationID  string    `json:"organization_id"`
 	LastRotatedTime time.Time `json:"last_rotated_time"`
 
+	// Egress TLS, proxy and timeout settings
+	CACertificate     string        `json:"ca_certificate,omitempty"`
+	ClientCertificate string        `json:"client_certificate,omitempty"`
+	ClientKey         string        `json:"client_key,omitempty"`
+	ProxyURL          string        `json:"proxy_url,omitempty"`
+	ConnectTimeout    time.Duration `json:"connect_timeout,omitempty"`
+	RequestTimeout    time.Duration `json:"request_timeout,omitempty"`
+
+	// APIEndpoints lists APIEndpoint followed by its failover endpoints. It
+	// is only stored when more than one endpoint is configured.
+	APIEndpoints     []string      `json:"api_endpoints,omitempty"`
+	EndpointCooldown time.Duration `json:"endpoint_cooldown,omitempty"`
+
+	// Outbound request headers. Sensitive header values are only kept in the
+	// seal-wrapped config entry and are never returned by reads.
+	CustomHeaders          map[string]string `json:"custom_headers,omitempty"`
+	SensitiveCustomHeaders map[string]string `json:"sensitive_custom_headers,omitempty"`
+	OpenAIBeta             string            `json:"openai_beta,omitempty"`
+
+	// RotationOverlap is how long a rotated-out admin key stays valid before
+	// it is revoked. RetiredAdminKeys are the keys awaiting revocation.
+	RotationOverlap  time.Duration     `json:"rotation_overlap,omitempty"`
+	RetiredAdminKeys []retiredAdminKey `json:"retired_admin_keys,omitempty"`
+
+	// Retry policy for the steps of an admin key rotation. Zero values use
+	// the defaults.
+	RotationRetryAttempts  int           `json:"rotation_retry_attempts,omitempty"`
+	RotationRetryBaseDelay time.Duration `json:"rotation_retry_base_delay,omitempty"`
+	RotationRetryMaxDelay  time.Duration `json:"rotation_retry_max_delay,omitempty"`
+
+	// Retry policy for individual OpenAI API requests. Zero values use the
+	// defaults.
+	RequestRetryAttempts  int           `json:"request_retry_attempts,omitempty"`
+	RequestRetryBaseDelay time.Duration `json:"request_retry_base_delay,omitempty"`
+	RequestRetryMaxDelay  time.Duration `json:"request_retry_max_delay,omitempty"`
+
+	// Client-side rate limiting of OpenAI API requests. Zero values use the
+	// defaults.
+	RateLimit         float64 `json:"rate_limit,omitempty"`
+	MaxInFlight       int     `json:"max_in_flight,omitempty"`
+	MaxQueuedRequests int     `json:"max_queued_requests,omitempty"`
+
+	// Circuit breaker for OpenAI API requests. Zero values use the defaults.
+	BreakerFailureThreshold int           `json:"breaker_failure_threshold,omitempty"`
+	BreakerCooldown         time.Duration `json:"breaker_cooldown,omitempty"`
+
+	// Version is incremented on every save and checked by
+	// putConnectionConfig, so a save based on a stale read is rejected.
+	Version uint64 `json:"version"`
+
+	// SchemaVersion is the storage schema version; see upgradeConfig.
+	SchemaVersion int `json:"schema_version"`
+
 	// Automated rotation configuration
 	automatedrotationutil.AutomatedRotationParams
 }
 
+// clientConfig returns the OpenAI client configuration for the named
+// connection. Every place that builds a client from stored config goes
+// through it, so egress settings are applied consistently.
+func (c *openaiConfig) clientConfig(name string) *Config {
+	return &Config{
+		Connection:        name,
+		AdminAPIKey:       c.AdminAPIKey,
+		AdminAPIKeyID:     c.AdminAPIKeyID,
+		APIEndpoint:       c.APIEndpoint,
+		OrganizationID:    c.OrganizationID,
+		CACertificate:     c.CACertificate,
+		ClientCertificate: c.ClientCertificate,
+		ClientKey:         c.ClientKey,
+		ProxyURL:          c.ProxyURL,
+		ConnectTimeout:    c.ConnectTimeout,
+		RequestTimeout:    c.RequestTimeout,
+		APIEndpoints:      c.APIEndpoints,
+		EndpointCooldown:  c.EndpointCooldown,
+
+		CustomHeaders:          c.CustomHeaders,
+		SensitiveCustomHeaders: c.SensitiveCustomHeaders,
+		OpenAIBeta:             c.OpenAIBeta,
+
+		RequestRetryAttempts:  c.RequestRetryAttempts,
+		RequestRetryBaseDelay: c.RequestRetryBaseDelay,
+		RequestRetryMaxDelay:  c.RequestRetryMaxDelay,
+
+		RateLimit:         c.RateLimit,
+		MaxInFlight:       c.MaxInFlight,
+		MaxQueuedRequests: c.MaxQueuedRequests,
+
+		BreakerFailureThreshold: c.BreakerFailureThreshold,
+		BreakerCooldown:         c.BreakerCooldown,
+	}
+}
+
+// apiEndpoints returns the connection's API endpoints in order of preference.
+func (c *openaiConfig) apiEndpoints() []string {
+	if len(c.APIEndpoints) > 0 {
+		return c.APIEndpoints
+	}
+	return []string{c.APIEndpoint}
+}
+
+// endpointCooldown returns the effective API endpoint cooldown.
+func (c *openaiConfig) endpointCooldown() time.Duration {
+	if c.EndpointCooldown == 0 {
+		return DefaultEndpointCooldown
+	}
+	return c.EndpointCooldown
+}
+
+// rotationRetryPolicy returns the retry policy for admin key rotation,
+// applying defaults for unset values.
+func (c *openaiConfig) rotationRetryPolicy() retryPolicy {
+	return retryPolicy{
+		Attempts:  c.RotationRetryAttempts,
+		BaseDelay: c.RotationRetryBaseDelay,
+		MaxDelay:  c.RotationRetryMaxDelay,
+	}.withDefaults(defaultRotationRetryPolicy)
+}
+
+// requestRetryPolicy returns the retry policy for OpenAI API requests,
+// applying defaults for unset values.
+func (c *openaiConfig) requestRetryPolicy() retryPolicy {
+	return retryPolicy{
+		Attempts:  c.RequestRetryAttempts,
+		BaseDelay: c.RequestRetryBaseDelay,
+		MaxDelay:  c.RequestRetryMaxDelay,
+	}.withDefaults(defaultRequestRetryPolicy)
+}
+
 // pathAdminConfig returns the path configuration for admin-level OpenAI config endpoints
 func (b *backend) pathAdminConfig() []*framework.Path {
 	return []*framework.Path{
@@ -56,40 +190,11 @@ func (b *backend) pathAdminConfig() []*framework.Path {
 				},
 			},
 			HelpSynopsis:    "Rotate the root admin API key",
-			HelpDescription: "Rotates the root admin API key used for accessing the OpenAI API. This creates a new admin API key and revokes the old one.",
+			HelpDescription: "Rotates the root admin API key used for accessing the OpenAI API. This creates a new admin API key and revokes the old one once rotation_overlap has elapsed.",
 		},
 		{
 			Pattern: configPath,
-			Fields: func() map[string]*framework.FieldSchema {
-				fields := map[string]*framework.FieldSchema{
-					"admin_api_key": {
-						Type:        framework.TypeString,
-						Description: "Admin API key used to manage project service accounts and API keys",
-						Required:    true,
-						DisplayAttrs: &framework.DisplayAttributes{
-							Sensitive: true,
-						},
-					},
-					"admin_api_key_id": {
-						Type:        framework.TypeString,
-						Description: "ID of the admin API key used to manage project service accounts and API keys",
-						Required:    true,
-					},
-					"organization_id": {
-						Type:        framework.TypeString,
-						Description: "Organization ID for the OpenAI account",
-						Required:    true,
-					},
-					"api_endpoint": {
-						Type:        framework.TypeString,
-						Description: "URL to the OpenAI API. Defaults to https://api.openai.com/v1",
-						Default:     DefaultAPIEndpoint,
-					},
-				}
-				// Add the automated rotation fields
-				automatedrotationutil.AddAutomatedRotationFields(fields)
-				return fields
-			}(),
+			Fields:  configFields(),
 			Operations: map[logical.Operation]framework.OperationHandler{
 				logical.ReadOperation: &framework.PathOperation{
 					Callback: b.pathConfigRead,
@@ -121,9 +226,185 @@ func (b *backend) pathAdminConfig() []*framework.Path {
 	}
 }
 
+// configFields returns the field schema shared by the default "config" path
+// and the named "config/<name>" connection paths.
+func configFields() map[string]*framework.FieldSchema {
+	fields := map[string]*framework.FieldSchema{
+		"admin_api_key": {
+			Type:        framework.TypeString,
+			Description: "Admin API key used to manage project service accounts and API keys",
+			Required:    true,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Sensitive: true,
+			},
+		},
+		"admin_api_key_id": {
+			Type:        framework.TypeString,
+			Description: "ID of the admin API key used to manage project service accounts and API keys. If omitted, it is discovered by matching admin_api_key against the organization's admin keys.",
+		},
+		"organization_id": {
+			Type:        framework.TypeString,
+			Description: "Organization ID for the OpenAI account",
+			Required:    true,
+		},
+		"api_endpoint": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: "URL to the OpenAI API, or a comma-separated list of URLs in order of preference. Requests fail over to the next URL on connection errors and 5xx responses. Defaults to https://api.openai.com/v1",
+			Default:     []string{DefaultAPIEndpoint},
+		},
+		"endpoint_cooldown": {
+			Type:        framework.TypeDurationSecond,
+			Description: "How long a failed API endpoint is tried only after the healthy ones. Defaults to 30 seconds.",
+		},
+		"custom_headers": {
+			Type:        framework.TypeKVPairs,
+			Description: "Static headers sent with every request to the OpenAI API, for example for an API gateway. Replaces any previously configured custom headers.",
+		},
+		"sensitive_custom_headers": {
+			Type:        framework.TypeKVPairs,
+			Description: "Static headers like custom_headers whose values are secret, such as gateway credentials. Their values are stored seal-wrapped and never returned.",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Sensitive: true,
+			},
+		},
+		"openai_beta": {
+			Type:        framework.TypeString,
+			Description: "Value of the OpenAI-Beta header. Defaults to " + DefaultOpenAIBeta + ".",
+		},
+		"ca_certificate": {
+			Type:        framework.TypeString,
+			Description: "PEM-encoded CA bundle to trust in addition to the system roots, e.g. for a TLS-intercepting egress proxy",
+		},
+		"client_certificate": {
+			Type:        framework.TypeString,
+			Description: "PEM-encoded client certificate for mutual TLS. Requires client_key.",
+		},
+		"client_key": {
+			Type:        framework.TypeString,
+			Description: "PEM-encoded private key for client_certificate",
+			DisplayAttrs: &framework.DisplayAttributes{
+				Sensitive: true,
+			},
+		},
+		"proxy_url": {
+			Type:        framework.TypeString,
+			Description: "URL of the HTTP(S) or SOCKS5 proxy used to reach the OpenAI API. Defaults to the HTTP_PROXY/HTTPS_PROXY environment of the plugin.",
+		},
+		"connect_timeout": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Timeout for establishing a connection, including the TLS handshake. Defaults to 30s.",
+		},
+		"request_timeout": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Timeout for a single request to the OpenAI API. Defaults to 30s.",
+		},
+		"verify_connection": {
+			Type:        framework.TypeBool,
+			Description: "Verify the admin API key and admin_api_key_id against OpenAI before saving the configuration. Defaults to true. A supplied admin_api_key_id is always checked against the admin API key.",
+			Default:     true,
+		},
+		"rotation_overlap": {
+			Type:        framework.TypeDurationSecond,
+			Description: "How long a rotated-out admin API key stays valid before it is revoked, so in-flight requests using it can finish. Defaults to 0, which revokes it immediately after rotation.",
+		},
+		"rotation_retry_attempts": {
+			Type:        framework.TypeInt,
+			Description: "Number of attempts for creating and validating a new admin API key during rotation. Defaults to 3.",
+		},
+		"rotation_retry_base_delay": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Delay before the first rotation retry; it doubles with each further retry. Defaults to 1 second.",
+		},
+		"rotation_retry_max_delay": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Maximum delay between rotation retries. Defaults to 30 seconds.",
+		},
+		"request_retry_attempts": {
+			Type:        framework.TypeInt,
+			Description: "Number of attempts for a request to the OpenAI API that fails with a rate limit, server error or connection error. Requests that create resources are only retried when OpenAI cannot have processed them. Set to 1 to disable retries. Defaults to 3.",
+		},
+		"request_retry_base_delay": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Delay before the first request retry; it doubles with each further retry and is jittered. Defaults to 1 second.",
+		},
+		"request_retry_max_delay": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Maximum delay between request retries. A request whose Retry-After asks for a longer wait is not retried. Defaults to 30 seconds.",
+		},
+		"rate_limit": {
+			Type:        framework.TypeFloat,
+			Description: "Maximum number of requests per second sent to the OpenAI API. The rate is lowered automatically when OpenAI reports its rate limit is nearly used up. Defaults to 10.",
+		},
+		"max_in_flight": {
+			Type:        framework.TypeInt,
+			Description: "Maximum number of concurrent requests sent to the OpenAI API. Defaults to 10.",
+		},
+		"max_queued_requests": {
+			Type:        framework.TypeInt,
+			Description: "Maximum number of requests waiting to be sent to the OpenAI API. Further requests fail with a retryable 503 error. Defaults to 100.",
+		},
+		"breaker_failure_threshold": {
+			Type:        framework.TypeInt,
+			Description: "Number of consecutive failed OpenAI API requests, counting connection errors and 5xx responses, after which requests fail fast without contacting OpenAI. Defaults to 5.",
+		},
+		"breaker_cooldown": {
+			Type:        framework.TypeDurationSecond,
+			Description: "How long requests fail fast once the failure threshold is reached. Afterwards a single request probes OpenAI, and its outcome resumes requests or starts another cooldown. Defaults to 30 seconds.",
+		},
+		"rotate_on_write": {
+			Type:        framework.TypeBool,
+			Description: "Rotate the admin API key immediately after saving the configuration, so the stored key has never been seen by a person. The supplied key is revoked right away, regardless of rotation_overlap.",
+			Default:     false,
+		},
+	}
+	// Add the automated rotation fields
+	automatedrotationutil.AddAutomatedRotationFields(fields)
+	return fields
+}
+
+// connectionName returns the connection a config request targets: the "name"
+// path parameter for config/<name>, or the default connection for "config".
+func connectionName(data *framework.FieldData) string {
+	if data != nil {
+		if raw, ok := data.GetOk("name"); ok {
+			if name, ok := raw.(string); ok && name != "" {
+				return name
+			}
+		}
+	}
+	return defaultConnectionName
+}
+
+// connectionStoragePath returns the storage path for a connection's config.
+// The default connection keeps the original "config" entry so existing
+// mounts continue to work unchanged.
+func connectionStoragePath(name string) string {
+	if name == "" || name == defaultConnectionName {
+		return configPath
+	}
+	return configPath + "/" + name
+}
+
+// connectionNameFromPath derives the connection name from a config request
+// path such as "config", "config/rotate", "config/prod" or
+// "config/prod/rotate". Vault's rotation manager calls back with the path the
+// rotation job was registered under, so this maps it to the right connection.
+func connectionNameFromPath(p string) string {
+	p = strings.TrimPrefix(strings.TrimPrefix(p, configPath), "/")
+	if p == "rotate" {
+		return defaultConnectionName
+	}
+	p = strings.TrimSuffix(p, "/rotate")
+	if p == "" {
+		return defaultConnectionName
+	}
+	return p
+}
+
 // pathConfigRead reads the configuration
 func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	config, err := getConfig(ctx, req.Storage)
+	name := connectionName(data)
+	config, err := getConnectionConfig(ctx, req.Storage, name)
 	if err != nil {
 		return nil, err
 	}
@@ -133,9 +414,64 @@ func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, data
 	}
 
 	respData := map[string]interface{}{
-		"api_endpoint":     config.APIEndpoint,
-		"organization_id":  config.OrganizationID,
-		"admin_api_key_id": config.AdminAPIKeyID,
+		"api_endpoint":       config.APIEndpoint,
+		"api_endpoints":      config.apiEndpoints(),
+		"endpoint_cooldown":  int64(config.endpointCooldown().Seconds()),
+		"organization_id":    config.OrganizationID,
+		"admin_api_key_id":   config.AdminAPIKeyID,
+		"ca_certificate":     config.CACertificate,
+		"client_certificate": config.ClientCertificate,
+		"proxy_url":          redactProxyURL(config.ProxyURL),
+		"connect_timeout":    int64(config.ConnectTimeout.Seconds()),
+		"request_timeout":    int64(config.RequestTimeout.Seconds()),
+		"rotation_overlap":   int64(config.RotationOverlap.Seconds()),
+		"retired_admin_keys": retiredAdminKeysResponse(config.RetiredAdminKeys),
+	}
+
+	respData["custom_headers"] = nonNilHeaders(config.CustomHeaders)
+	respData["sensitive_custom_headers"] = sortedHeaderNames(config.SensitiveCustomHeaders)
+	respData["openai_beta"] = config.OpenAIBeta
+	if config.OpenAIBeta == "" {
+		respData["openai_beta"] = DefaultOpenAIBeta
+	}
+
+	policy := config.rotationRetryPolicy()
+	respData["rotation_retry_attempts"] = policy.Attempts
+	respData["rotation_retry_base_delay"] = int64(policy.BaseDelay.Seconds())
+	respData["rotation_retry_max_delay"] = int64(policy.MaxDelay.Seconds())
+
+	requestPolicy := config.requestRetryPolicy()
+	respData["request_retry_attempts"] = requestPolicy.Attempts
+	respData["request_retry_base_delay"] = int64(requestPolicy.BaseDelay.Seconds())
+	respData["request_retry_max_delay"] = int64(requestPolicy.MaxDelay.Seconds())
+
+	respData["rate_limit"] = config.RateLimit
+	if config.RateLimit == 0 {
+		respData["rate_limit"] = DefaultRateLimit
+	}
+	respData["max_in_flight"] = config.MaxInFlight
+	if config.MaxInFlight == 0 {
+		respData["max_in_flight"] = DefaultMaxInFlight
+	}
+	respData["max_queued_requests"] = config.MaxQueuedRequests
+	if config.MaxQueuedRequests == 0 {
+		respData["max_queued_requests"] = DefaultMaxQueuedRequests
+	}
+	respData["breaker_failure_threshold"] = config.BreakerFailureThreshold
+	if config.BreakerFailureThreshold == 0 {
+		respData["breaker_failure_threshold"] = DefaultBreakerFailureThreshold
+	}
+	respData["breaker_cooldown"] = int64(config.BreakerCooldown.Seconds())
+	if config.BreakerCooldown == 0 {
+		respData["breaker_cooldown"] = int64(DefaultBreakerCooldown.Seconds())
+	}
+
+	// Endpoint health lives in the cached client; a connection without one
+	// has not made a request yet, so its primary endpoint is active.
+	respData["active_endpoint"] = config.APIEndpoint
+	if client, ok := b.getClient(name).(*Client); ok {
+		respData["active_endpoint"] = client.ActiveEndpoint()
+		respData["endpoint_health"] = endpointHealthResponse(client.endpointStatus())
 	}
 
 	// Add automated rotation parameters to the response
@@ -154,20 +490,45 @@ func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, data
 
 // pathConfigWrite updates the configuration
 func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := connectionName(data)
+
+	resp, err := b.writeConnectionConfig(ctx, req, data, name)
+	if err != nil || (resp != nil && resp.IsError()) {
+		return resp, err
+	}
+
+	// Rotation takes the config lock itself, so it runs once the write has
+	// released it.
+	if data.Get("rotate_on_write").(bool) {
+		return b.rotateOnWrite(ctx, req.Storage, name, resp), nil
+	}
+	return resp, nil
+}
+
+// writeConnectionConfig validates and saves the named connection's config.
+// It holds the connection's config lock throughout, so a concurrent rotation
+// cannot be overwritten with the stale admin key read here.
+func (b *backend) writeConnectionConfig(ctx context.Context, req *logical.Request, data *framework.FieldData, name string) (*logical.Response, error) {
+	lock := b.configLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// Get the configuration
-	config, err := getConfig(ctx, req.Storage)
+	config, err := getConnectionConfig(ctx, req.Storage, name)
 	if err != nil {
 		return nil, err
 	}
 
 	// Initialize config if it doesn't exist
 	if config == nil {
-		config = &openaiConfig{}
+		config = &openaiConfig{SchemaVersion: configSchemaVersion}
 	}
 
 	// Update values from request data
+	adminAPIKeyChanged := false
 	adminAPIKey, ok := data.GetOk("admin_api_key")
 	if ok {
+		adminAPIKeyChanged = adminAPIKey.(string) != config.AdminAPIKey
 		config.AdminAPIKey = adminAPIKey.(string)
 		if !hasExpectedAdminAPIKeyPrefix(config.AdminAPIKey) {
 			b.Logger().Warn("configured admin_api_key does not use the expected OpenAI admin key prefix",
@@ -175,17 +536,17 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 		}
 	}
 
-	adminAPIKeyID, ok := data.GetOk("admin_api_key_id")
-	if ok {
+	adminAPIKeyID, adminAPIKeyIDSupplied := data.GetOk("admin_api_key_id")
+	if adminAPIKeyIDSupplied {
 		config.AdminAPIKeyID = adminAPIKeyID.(string)
+	} else if adminAPIKeyChanged {
+		// The stored ID belongs to the previous key; discover the new one.
+		config.AdminAPIKeyID = ""
 	}
 
 	if config.AdminAPIKey == "" {
 		return logical.ErrorResponse("admin_api_key is required"), nil
 	}
-	if config.AdminAPIKeyID == "" {
-		return logical.ErrorResponse("admin_api_key_id is required"), nil
-	}
 
 	organizationID, ok := data.GetOk("organization_id")
 	if ok {
@@ -196,15 +557,118 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 		return logical.ErrorResponse("organization_id is required"), nil
 	}
 
-	apiEndpoint, ok := data.GetOk("api_endpoint")
-	if ok {
-		config.APIEndpoint = apiEndpoint.(string)
+	if apiEndpoints, ok := data.GetOk("api_endpoint"); ok {
+		var endpoints []string
+		for _, endpoint := range apiEndpoints.([]string) {
+			if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+		config.APIEndpoint = ""
+		config.APIEndpoints = nil
+		if len(endpoints) > 0 {
+			config.APIEndpoint = endpoints[0]
+		}
+		if len(endpoints) > 1 {
+			config.APIEndpoints = endpoints
+		}
 	}
 
 	if config.APIEndpoint == "" {
 		config.APIEndpoint = DefaultAPIEndpoint
 	}
 
+	if cooldown, ok := data.GetOk("endpoint_cooldown"); ok {
+		config.EndpointCooldown = time.Duration(cooldown.(int)) * time.Second
+	}
+	if config.EndpointCooldown < 0 {
+		return logical.ErrorResponse("endpoint_cooldown must not be negative"), nil
+	}
+
+	if customHeaders, ok := data.GetOk("custom_headers"); ok {
+		config.CustomHeaders = customHeaders.(map[string]string)
+	}
+	if sensitiveHeaders, ok := data.GetOk("sensitive_custom_headers"); ok {
+		config.SensitiveCustomHeaders = sensitiveHeaders.(map[string]string)
+	}
+	if openAIBeta, ok := data.GetOk("openai_beta"); ok {
+		config.OpenAIBeta = openAIBeta.(string)
+	}
+
+	if caCertificate, ok := data.GetOk("ca_certificate"); ok {
+		config.CACertificate = caCertificate.(string)
+	}
+	if clientCertificate, ok := data.GetOk("client_certificate"); ok {
+		config.ClientCertificate = clientCertificate.(string)
+	}
+	if clientKey, ok := data.GetOk("client_key"); ok {
+		config.ClientKey = clientKey.(string)
+	}
+	if proxyURL, ok := data.GetOk("proxy_url"); ok {
+		config.ProxyURL = proxyURL.(string)
+	}
+	if connectTimeout, ok := data.GetOk("connect_timeout"); ok {
+		config.ConnectTimeout = time.Duration(connectTimeout.(int)) * time.Second
+	}
+	if requestTimeout, ok := data.GetOk("request_timeout"); ok {
+		config.RequestTimeout = time.Duration(requestTimeout.(int)) * time.Second
+	}
+	if rotationOverlap, ok := data.GetOk("rotation_overlap"); ok {
+		config.RotationOverlap = time.Duration(rotationOverlap.(int)) * time.Second
+	}
+	if config.RotationOverlap < 0 {
+		return logical.ErrorResponse("rotation_overlap must not be negative"), nil
+	}
+
+	if attempts, ok := data.GetOk("rotation_retry_attempts"); ok {
+		config.RotationRetryAttempts = attempts.(int)
+	}
+	if baseDelay, ok := data.GetOk("rotation_retry_base_delay"); ok {
+		config.RotationRetryBaseDelay = time.Duration(baseDelay.(int)) * time.Second
+	}
+	if maxDelay, ok := data.GetOk("rotation_retry_max_delay"); ok {
+		config.RotationRetryMaxDelay = time.Duration(maxDelay.(int)) * time.Second
+	}
+	if err := config.rotationRetryPolicy().validate("rotation"); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if attempts, ok := data.GetOk("request_retry_attempts"); ok {
+		config.RequestRetryAttempts = attempts.(int)
+	}
+	if baseDelay, ok := data.GetOk("request_retry_base_delay"); ok {
+		config.RequestRetryBaseDelay = time.Duration(baseDelay.(int)) * time.Second
+	}
+	if maxDelay, ok := data.GetOk("request_retry_max_delay"); ok {
+		config.RequestRetryMaxDelay = time.Duration(maxDelay.(int)) * time.Second
+	}
+	if err := config.requestRetryPolicy().validate("request"); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if rateLimit, ok := data.GetOk("rate_limit"); ok {
+		config.RateLimit = rateLimit.(float64)
+	}
+	if maxInFlight, ok := data.GetOk("max_in_flight"); ok {
+		config.MaxInFlight = maxInFlight.(int)
+	}
+	if maxQueued, ok := data.GetOk("max_queued_requests"); ok {
+		config.MaxQueuedRequests = maxQueued.(int)
+	}
+	if err := validateAdmissionLimits(config.RateLimit, config.MaxInFlight, config.MaxQueuedRequests); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if threshold, ok := data.GetOk("breaker_failure_threshold"); ok {
+		config.BreakerFailureThreshold = threshold.(int)
+	}
+	if cooldown, ok := data.GetOk("breaker_cooldown"); ok {
+		config.BreakerCooldown = time.Duration(cooldown.(int)) * time.Second
+	}
+	if err := validateBreakerSettings(config.BreakerFailureThreshold, config.BreakerCooldown); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	// Parse automated rotation parameters
 	if err := config.ParseAutomatedRotationFields(data); err != nil {
 		return logical.ErrorResponse("error parsing automated rotation fields: %s", err), nil
@@ -216,16 +680,36 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 	}
 
 	// Create a test client to validate the configuration
-	client := NewClient(config.AdminAPIKey, b.Logger())
-	clientConfig := &Config{
-		AdminAPIKey:    config.AdminAPIKey,
-		AdminAPIKeyID:  config.AdminAPIKeyID,
-		APIEndpoint:    config.APIEndpoint,
-		OrganizationID: config.OrganizationID,
+	client, err := b.newClient(config.clientConfig(name), b.Logger())
+	if err != nil {
+		return logical.ErrorResponse("error validating OpenAI configuration: %s", err), nil
 	}
 
-	if err := client.SetConfig(clientConfig); err != nil {
-		return logical.ErrorResponse("error validating OpenAI configuration: %s", err), nil
+	// Resolve admin_api_key_id from OpenAI when it was not supplied, so
+	// rotation never revokes the wrong key.
+	if config.AdminAPIKeyID == "" {
+		keyID, err := client.DiscoverAdminAPIKeyID(ctx)
+		if err != nil {
+			return logical.ErrorResponse("error discovering admin_api_key_id: %s", err), nil
+		}
+		b.Logger().Debug("Discovered admin API key ID for the configured admin key")
+		config.AdminAPIKeyID = keyID
+	}
+
+	// Contact OpenAI so a wrong key, key ID or organization is reported now
+	// rather than at the first role write. A supplied admin_api_key_id is
+	// checked even without verify_connection: rotation revokes the key it
+	// names.
+	var respData map[string]interface{}
+	if data.Get("verify_connection").(bool) {
+		respData, err = verifyConnection(ctx, client, config.AdminAPIKey, config.AdminAPIKeyID)
+		if err != nil {
+			return logical.ErrorResponse("error verifying OpenAI connection: %s", err), nil
+		}
+	} else if adminAPIKeyIDSupplied && config.AdminAPIKeyID != "" {
+		if _, err := verifyAdminAPIKeyID(ctx, client, config.AdminAPIKey, config.AdminAPIKeyID); err != nil {
+			return logical.ErrorResponse("error verifying admin_api_key_id: %s", err), nil
+		}
 	}
 
 	var performedRotationManagerOperation string
@@ -259,12 +743,7 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 	}
 
 	// Save the configuration
-	entry, err := logical.StorageEntryJSON(configPath, config)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := req.Storage.Put(ctx, entry); err != nil {
+	if err := putConnectionConfig(ctx, req.Storage, name, config); err != nil {
 		wrappedError := err
 		if performedRotationManagerOperation != "" {
 			b.Logger().Error("write to storage failed but the rotation manager still succeeded.",
@@ -277,12 +756,94 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 		return nil, wrappedError
 	}
 
-	// Update backend client under the write lock.
-	b.Lock()
-	b.client = client
-	b.Unlock()
+	// Update the connection's cached client under the write lock.
+	b.setClient(name, client)
 
-	return nil, nil
+	if respData == nil {
+		return nil, nil
+	}
+	return &logical.Response{Data: respData}, nil
+}
+
+// rotateOnWrite replaces a freshly written bootstrap admin key with a new one
+// that only Vault knows, and revokes the bootstrap key right away, whatever
+// the connection's rotation_overlap. The configuration is already saved, so
+// a failed rotation or revocation is reported as a warning rather than
+// failing the write. The write's response, if any, is extended with the
+// rotation's outcome.
+func (b *backend) rotateOnWrite(ctx context.Context, storage logical.Storage, name string, resp *logical.Response) *logical.Response {
+	if resp == nil {
+		resp = &logical.Response{}
+	}
+	if resp.Data == nil {
+		resp.Data = make(map[string]interface{})
+	}
+	resp.Data["rotated"] = false
+
+	rotation, err := b.rotateConnectionCredential(ctx, storage, name, rotationTriggerConfigWrite)
+	if err != nil {
+		b.Logger().Error("rotate_on_write failed; the configured admin API key remains in use", "connection", name, "error", err)
+		resp.AddWarning(fmt.Sprintf("configuration saved, but rotating the admin API key failed: %s", err))
+		return resp
+	}
+	resp.Data["rotated"] = true
+	// A verified admin_api_key_name and admin_api_key_redacted_value still
+	// describe the supplied key, as they did before the rotation.
+	resp.Data["admin_api_key_id"] = rotation.NewKeyID
+
+	if rotation.OldKeyID != "" {
+		if err := b.revokeRetiredAdminKey(ctx, storage, name, rotation.OldKeyID); err != nil {
+			b.Logger().Error("rotate_on_write could not revoke the bootstrap admin key; it is retried in the background", "connection", name, "error", err)
+			resp.AddWarning(fmt.Sprintf("admin API key rotated, but revoking the supplied admin API key %s failed; it remains valid until a background retry revokes it: %s",
+				rotation.OldKeyID, err))
+		}
+	}
+
+	cfg, err := getConnectionConfig(ctx, storage, name)
+	if err != nil || cfg == nil {
+		resp.AddWarning("admin API key rotated, but the updated configuration could not be read back")
+		return resp
+	}
+	resp.Data["rotated_time"] = cfg.LastRotatedTime.Format(time.RFC3339)
+	return resp
+}
+
+// verifyConnection checks that the admin credentials can reach OpenAI and that
+// admin_api_key_id names the configured admin key. It returns the key's name,
+// ID and redacted value so the operator can confirm the right key was
+// configured.
+func verifyConnection(ctx context.Context, client ClientAPI, adminAPIKey, adminAPIKeyID string) (map[string]interface{}, error) {
+	if err := client.TestConnection(ctx); err != nil {
+		return nil, err
+	}
+
+	key, err := verifyAdminAPIKeyID(ctx, client, adminAPIKey, adminAPIKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"admin_api_key_id":             adminAPIKeyID,
+		"admin_api_key_name":           key.Name,
+		"admin_api_key_redacted_value": key.RedactedValue,
+	}, nil
+}
+
+// verifyAdminAPIKeyID checks that admin_api_key_id names the configured admin
+// key by comparing the key with the redacted value OpenAI reports for the ID.
+// A key without a redacted value cannot be verified and is rejected.
+func verifyAdminAPIKeyID(ctx context.Context, client ClientAPI, adminAPIKey, adminAPIKeyID string) (*AdminAPIKey, error) {
+	key, err := client.GetAdminAPIKey(ctx, adminAPIKeyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.RedactedValue == "" {
+		return nil, fmt.Errorf("OpenAI returned no redacted value for admin_api_key_id %q, so it cannot be verified against the configured admin_api_key", adminAPIKeyID)
+	}
+	if !matchesRedactedValue(adminAPIKey, key.RedactedValue) {
+		return nil, fmt.Errorf("admin_api_key_id %q does not match the configured admin_api_key", adminAPIKeyID)
+	}
+	return key, nil
 }
 
 // pathConfigDelete deletes the configuration
@@ -291,10 +852,24 @@ func (b *backend) pathConfigDelete(ctx context.Context, req *logical.Request, da
 	// stored configuration. Rotation jobs are managed separately from plugin
 	// storage, so leaving one registered could cause later rotation attempts to
 	// run after the config is gone.
-	config, err := getConfig(ctx, req.Storage)
+	name := connectionName(data)
+
+	lock := b.configLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	config, err := getConnectionConfig(ctx, req.Storage, name)
 	if err != nil {
 		return nil, err
 	}
+	// Once the config is gone, keys left by incomplete rotations can no
+	// longer be revoked, so they are revoked first.
+	if config != nil {
+		if err := b.rollbackConnectionRotations(ctx, req.Storage, name, config); err != nil {
+			return logical.ErrorResponse("error revoking the admin API key of an incomplete rotation; retry the delete once OpenAI is reachable: %s", err), nil
+		}
+	}
+
 	if config != nil && config.ShouldRegisterRotationJob() {
 		deregisterReq := &rotation.RotationJobDeregisterRequest{
 			MountPoint: req.MountPoint,
@@ -306,43 +881,152 @@ func (b *backend) pathConfigDelete(ctx context.Context, req *logical.Request, da
 		}
 	}
 
-	if err := req.Storage.Delete(ctx, configPath); err != nil {
+	if err := req.Storage.Delete(ctx, connectionStoragePath(name)); err != nil {
 		return nil, err
 	}
-	b.Lock()
-	b.client = nil
-	b.Unlock()
+	if err := req.Storage.Delete(ctx, rotationStatusStoragePath(name)); err != nil {
+		b.Logger().Warn("failed to delete rotation status during config delete", "error", err)
+	}
+	b.setClient(name, nil)
+	b.transports.remove(name)
+	b.admissions.remove(name)
+	b.breakers.remove(name)
+
+	if config != nil && len(config.RetiredAdminKeys) > 0 {
+		resp := &logical.Response{}
+		resp.AddWarning(fmt.Sprintf("%d retired admin API key(s) were pending revocation and must be revoked in OpenAI manually: %s",
+			len(config.RetiredAdminKeys), strings.Join(retiredAdminKeyIDs(config.RetiredAdminKeys), ", ")))
+		return resp, nil
+	}
 	return nil, nil
 }
 
-// getConfig returns the configuration for this backend
+// retiredAdminKeyIDs returns the IDs of the given retired keys.
+func retiredAdminKeyIDs(keys []retiredAdminKey) []string {
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	return ids
+}
+
+// retiredAdminKeysResponse formats the retired keys awaiting revocation for
+// config reads.
+func retiredAdminKeysResponse(keys []retiredAdminKey) []map[string]interface{} {
+	resp := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		entry := map[string]interface{}{
+			"admin_api_key_id": key.ID,
+			"retired_at":       key.RetiredAt.Format(time.RFC3339),
+			"revoke_after":     key.RevokeAfter.Format(time.RFC3339),
+			"attempts":         key.Attempts,
+		}
+		if key.LastError != "" {
+			entry["last_error"] = key.LastError
+		}
+		resp = append(resp, entry)
+	}
+	return resp
+}
+
+// nonNilHeaders returns headers, or an empty map if it is nil, so reads
+// always return an object.
+func nonNilHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return map[string]string{}
+	}
+	return headers
+}
+
+// endpointHealthResponse formats API endpoint health for config reads.
+func endpointHealthResponse(statuses []endpointStatus) []map[string]interface{} {
+	resp := make([]map[string]interface{}, 0, len(statuses))
+	for _, status := range statuses {
+		entry := map[string]interface{}{
+			"endpoint": status.URL,
+			"healthy":  status.Healthy,
+			"failures": status.Failures,
+		}
+		if status.LastError != "" {
+			entry["last_error"] = status.LastError
+		}
+		if !status.Healthy {
+			entry["unhealthy_until"] = status.UnhealthyUntil.Format(time.RFC3339)
+		}
+		resp = append(resp, entry)
+	}
+	return resp
+}
+
+// getConfig returns the configuration of the default connection
 func getConfig(ctx context.Context, s logical.Storage) (*openaiConfig, error) {
-	entry, err := s.Get(ctx, configPath)
+	return getConnectionConfig(ctx, s, defaultConnectionName)
+}
+
+// getConnectionConfig returns the configuration for the named connection
+func getConnectionConfig(ctx context.Context, s logical.Storage, name string) (*openaiConfig, error) {
+	config, _, err := readConnectionConfig(ctx, s, name)
+	return config, err
+}
+
+// readConnectionConfig returns the named connection's configuration upgraded
+// to the current schema version, and whether an upgrade was applied.
+func readConnectionConfig(ctx context.Context, s logical.Storage, name string) (*openaiConfig, bool, error) {
+	entry, err := s.Get(ctx, connectionStoragePath(name))
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if entry == nil {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	config := &openaiConfig{}
 	if err := entry.DecodeJSON(config); err != nil {
-		return nil, fmt.Errorf("error reading OpenAI configuration: %w", err)
+		return nil, false, fmt.Errorf("error reading OpenAI configuration: %w", err)
+	}
+
+	return config, upgradeConfig(config), nil
+}
+
+// putConnectionConfig saves the configuration for the named connection. It is
+// a compare-and-swap on Version: the save fails with errConfigConflict unless
+// the stored entry is still at the version config was read at, and on success
+// config.Version is advanced. Callers must hold the connection's config lock.
+func putConnectionConfig(ctx context.Context, s logical.Storage, name string, config *openaiConfig) error {
+	current, err := getConnectionConfig(ctx, s, name)
+	if err != nil {
+		return err
+	}
+	var currentVersion uint64
+	if current != nil {
+		currentVersion = current.Version
+	}
+	if currentVersion != config.Version {
+		return errConfigConflict
 	}
 
-	return config, nil
+	config.Version++
+	entry, err := logical.StorageEntryJSON(connectionStoragePath(name), config)
+	if err == nil {
+		err = s.Put(ctx, entry)
+	}
+	if err != nil {
+		config.Version--
+		return err
+	}
+	return nil
 }
 
 // validateProject validates a project ID with OpenAI API without caching
 // This simplifies the codebase by removing project storage and caching logic
-func (b *backend) validateProject(ctx context.Context, s logical.Storage, projectID string) (*ProjectInfo, error) {
+func (b *backend) validateProject(ctx context.Context, s logical.Storage, connection, projectID string) (*ProjectInfo, error) {
 	if projectID == "" {
 		return nil, fmt.Errorf("project ID is required")
 	}
 
 	// Validate project with OpenAI API
-	client, err := b.configuredClient(ctx, s)
+	client, err := b.configuredClient(ctx, s, connection)
 	if err != nil {
 		return nil, err
 	}
@@ -359,11 +1043,12 @@ func (b *backend) validateProject(ctx context.Context, s logical.Storage, projec
 
 // pathConfigRotateRoot handles manual rotation of the admin API key
 func (b *backend) pathConfigRotateRoot(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	if err := b.rotateRootCredential(ctx, req); err != nil {
+	name := connectionName(data)
+	if _, err := b.rotateConnectionCredential(ctx, req.Storage, name, rotationTriggerManual); err != nil {
 		return nil, err
 	}
 
-	cfg, err := getConfig(ctx, req.Storage)
+	cfg, err := getConnectionConfig(ctx, req.Storage, name)
 	if err != nil {
 		return nil, fmt.Errorf("rotated credentials but failed to reload config: %w", err)
 	}
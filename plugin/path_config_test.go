@@ -6,8 +6,13 @@ package openaisecrets
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/framework"
@@ -16,6 +21,30 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// offlineAdminKeyLookups makes the backend's clients answer admin key
+// lookups locally, reporting every key ID as belonging to the configured
+// admin key, so config writes can verify admin_api_key_id without OpenAI.
+func offlineAdminKeyLookups(b *backend) {
+	b.newClient = func(config *Config, logger hclog.Logger) (ClientAPI, error) {
+		client, err := newOpenAIClient(b.transports, b.admissions, b.breakers, b.tracerProvider)(config, logger)
+		if err != nil {
+			return nil, err
+		}
+		c := client.(*Client)
+		c.httpClient.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			body := fmt.Sprintf(`{"object": "organization.admin_api_key", "id": %q, "name": "admin-key", "redacted_value": %q}`,
+				path.Base(req.URL.Path), redactMockKey(config.AdminAPIKey))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		})
+		return c, nil
+	}
+}
+
 func TestConfig_Paths(t *testing.T) {
 	b := getTestBackend(t)
 
@@ -26,17 +55,19 @@ func TestConfig_Paths(t *testing.T) {
 
 func TestConfig_AdminConfig_CRUD(t *testing.T) {
 	b := getTestBackend(t)
+	offlineAdminKeyLookups(b)
 	storage := &logical.InmemStorage{}
 	ctx := context.Background()
 
 	// Create config (all required fields present)
 	createData := &framework.FieldData{
 		Raw: map[string]interface{}{
-			"admin_api_key":    "test-key",
-			"admin_api_key_id": "test-admin-key-id",
-			"organization_id":  "org-123",
-			"api_endpoint":     "https://api.test.com/v1",
-			"rotation_period":  0, // Required field
+			"admin_api_key":     "test-key",
+			"admin_api_key_id":  "test-admin-key-id",
+			"organization_id":   "org-123",
+			"api_endpoint":      "https://api.test.com/v1",
+			"rotation_period":   0, // Required field
+			"verify_connection": false,
 		},
 		Schema: b.pathAdminConfig()[1].Fields,
 	}
@@ -52,9 +83,10 @@ func TestConfig_AdminConfig_CRUD(t *testing.T) {
 	// Test missing admin_api_key
 	missingKeyData := &framework.FieldData{
 		Raw: map[string]interface{}{
-			"organization_id": "org-123",
-			"api_endpoint":    "https://api.test.com/v1",
-			"rotation_period": 0, // Required field
+			"organization_id":   "org-123",
+			"api_endpoint":      "https://api.test.com/v1",
+			"rotation_period":   0, // Required field
+			"verify_connection": false,
 		},
 		Schema: b.pathAdminConfig()[1].Fields,
 	}
@@ -90,11 +122,12 @@ func TestConfig_AdminConfig_CRUD(t *testing.T) {
 	// Update config with AdminAPIKeyID
 	updateData := &framework.FieldData{
 		Raw: map[string]interface{}{
-			"admin_api_key":    "updated-key",
-			"admin_api_key_id": "updated-key-id",
-			"organization_id":  "org-456",
-			"api_endpoint":     "https://api.test.com/v1",
-			"rotation_period":  0, // Required field
+			"admin_api_key":     "updated-key",
+			"admin_api_key_id":  "updated-key-id",
+			"organization_id":   "org-456",
+			"api_endpoint":      "https://api.test.com/v1",
+			"rotation_period":   0, // Required field
+			"verify_connection": false,
 		},
 		Schema: b.pathAdminConfig()[1].Fields,
 	}
@@ -141,6 +174,7 @@ func TestConfig_AdminConfig_CRUD(t *testing.T) {
 
 func TestConfigWrite_WarnsWhenAdminKeyPrefixUnexpected(t *testing.T) {
 	b := getTestBackend(t)
+	offlineAdminKeyLookups(b)
 	var logs bytes.Buffer
 	b.logger = hclog.New(&hclog.LoggerOptions{
 		Level:  hclog.Warn,
@@ -157,11 +191,12 @@ func TestConfigWrite_WarnsWhenAdminKeyPrefixUnexpected(t *testing.T) {
 		Path:       "config",
 	}, &framework.FieldData{
 		Raw: map[string]interface{}{
-			"admin_api_key":    unexpectedKey,
-			"admin_api_key_id": "test-admin-key-id",
-			"organization_id":  "org-123",
-			"api_endpoint":     "https://api.test.com/v1",
-			"rotation_period":  0,
+			"admin_api_key":     unexpectedKey,
+			"admin_api_key_id":  "test-admin-key-id",
+			"organization_id":   "org-123",
+			"api_endpoint":      "https://api.test.com/v1",
+			"rotation_period":   0,
+			"verify_connection": false,
 		},
 		Schema: b.pathAdminConfig()[1].Fields,
 	})
@@ -173,3 +208,313 @@ func TestConfigWrite_WarnsWhenAdminKeyPrefixUnexpected(t *testing.T) {
 	assert.Contains(t, logOutput, adminAPIKeyPrefix)
 	assert.False(t, strings.Contains(logOutput, unexpectedKey), "admin API key value must not be logged")
 }
+
+func TestConfigWrite_VerifyConnection(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	ctx := context.Background()
+
+	write := func(storage logical.Storage, keyID string) *logical.Response {
+		resp, err := b.pathConfigWrite(ctx, &logical.Request{
+			Storage:    storage,
+			MountPoint: TestMountPoint,
+			Path:       TestConfigPath,
+		}, &framework.FieldData{
+			Raw: map[string]interface{}{
+				"admin_api_key":    TestAPIKey,
+				"admin_api_key_id": keyID,
+				"organization_id":  TestOrganizationID,
+				"api_endpoint":     mockServer.URL() + "/v1",
+			},
+			Schema: b.pathAdminConfig()[1].Fields,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Verification is on by default and reports the key it found.
+	storage := &logical.InmemStorage{}
+	resp := write(storage, TestAdminAPIKeyID)
+	require.NotNil(t, resp)
+	require.False(t, resp.IsError(), "unexpected error: %v", resp.Error())
+	assert.Equal(t, "sample-admin-key", resp.Data["admin_api_key_name"])
+	assert.Equal(t, redactMockKey(TestAPIKey), resp.Data["admin_api_key_redacted_value"])
+
+	// A key ID that does not exist in the organization is rejected and the
+	// configuration is not saved.
+	storage = &logical.InmemStorage{}
+	resp = write(storage, "key_does_not_exist")
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "error verifying OpenAI connection")
+
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Nil(t, cfg, "config must not be saved when verification fails")
+}
+
+func TestConfigWrite_AdminAPIKeyIDAlwaysVerified(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+	mockServer.AddAdminAPIKey("key_other", "other-admin-key", "sk-admin-other-0000")
+
+	b := getTestBackend(t)
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	write := func(keyID string) *logical.Response {
+		resp, err := b.pathConfigWrite(ctx, &logical.Request{
+			Storage:    storage,
+			MountPoint: TestMountPoint,
+			Path:       TestConfigPath,
+		}, &framework.FieldData{
+			Raw: map[string]interface{}{
+				"admin_api_key":     TestAPIKey,
+				"admin_api_key_id":  keyID,
+				"organization_id":   TestOrganizationID,
+				"api_endpoint":      mockServer.URL() + "/v1",
+				"verify_connection": false,
+			},
+			Schema: b.pathAdminConfig()[1].Fields,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// A supplied ID is checked even when verify_connection is off.
+	resp := write("key_other")
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "does not match")
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Nil(t, cfg, "config must not be saved when the key ID does not match")
+
+	resp = write(TestAdminAPIKeyID)
+	assert.Nil(t, resp)
+
+	// A key without a redacted value cannot be verified.
+	var configs []*Config
+	b.newClient = fakeClientFactory(map[string]*mockClient{TestAPIKey: {}}, &configs)
+	resp = write("key_unredacted")
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "cannot be verified")
+	cfg, err = getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, TestAdminAPIKeyID, cfg.AdminAPIKeyID, "stored ID must be unchanged")
+}
+
+func TestConfigWrite_AdminAPIKeyIDDiscovery(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+	mockServer.AddAdminAPIKey("key_other", "other-admin-key", "sk-admin-other-0000")
+
+	b := getTestBackend(t)
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	write := func(raw map[string]interface{}) *logical.Response {
+		raw["organization_id"] = TestOrganizationID
+		raw["api_endpoint"] = mockServer.URL() + "/v1"
+		resp, err := b.pathConfigWrite(ctx, &logical.Request{
+			Storage:    storage,
+			MountPoint: TestMountPoint,
+			Path:       TestConfigPath,
+		}, &framework.FieldData{Raw: raw, Schema: b.pathAdminConfig()[1].Fields})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Omitting admin_api_key_id discovers it from the key's redacted value.
+	resp := write(map[string]interface{}{"admin_api_key": TestAPIKey})
+	require.NotNil(t, resp)
+	require.False(t, resp.IsError(), "unexpected error: %v", resp.Error())
+	assert.Equal(t, TestAdminAPIKeyID, resp.Data["admin_api_key_id"])
+	config, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, TestAdminAPIKeyID, config.AdminAPIKeyID)
+
+	// A supplied ID that belongs to a different key is refused.
+	resp = write(map[string]interface{}{"admin_api_key": TestAPIKey, "admin_api_key_id": "key_other"})
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "does not match")
+	config, err = getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, TestAdminAPIKeyID, config.AdminAPIKeyID, "stored ID must be unchanged")
+
+	// Changing the key without an ID re-discovers the ID for the new key.
+	resp = write(map[string]interface{}{"admin_api_key": "sk-admin-other-0000"})
+	require.NotNil(t, resp)
+	require.False(t, resp.IsError(), "unexpected error: %v", resp.Error())
+	config, err = getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, "key_other", config.AdminAPIKeyID)
+
+	// An ambiguous match fails clearly.
+	mockServer.AddAdminAPIKey("key_duplicate", "duplicate-admin-key", TestAPIKey)
+	resp = write(map[string]interface{}{"admin_api_key": TestAPIKey})
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "admin_api_key_id explicitly")
+}
+
+func TestConfigWrite_RotationRetryValidation(t *testing.T) {
+	b := getTestBackend(t)
+
+	resp, err := b.pathConfigWrite(context.Background(), &logical.Request{
+		Storage:    &logical.InmemStorage{},
+		MountPoint: TestMountPoint,
+		Path:       TestConfigPath,
+	}, &framework.FieldData{
+		Raw: map[string]interface{}{
+			"admin_api_key":             TestAPIKey,
+			"admin_api_key_id":          TestAdminAPIKeyID,
+			"organization_id":           TestOrganizationID,
+			"verify_connection":         false,
+			"rotation_retry_base_delay": 60,
+			"rotation_retry_max_delay":  10,
+		},
+		Schema: b.pathAdminConfig()[1].Fields,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+}
+
+func TestConfigWrite_RequestRetry(t *testing.T) {
+	b := getTestBackend(t)
+	offlineAdminKeyLookups(b)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	req := &logical.Request{Storage: storage, MountPoint: TestMountPoint, Path: TestConfigPath}
+
+	resp, err := b.pathConfigWrite(ctx, req, &framework.FieldData{
+		Raw: map[string]interface{}{
+			"admin_api_key":            TestAPIKey,
+			"admin_api_key_id":         TestAdminAPIKeyID,
+			"organization_id":          TestOrganizationID,
+			"verify_connection":        false,
+			"request_retry_base_delay": 60,
+			"request_retry_max_delay":  10,
+		},
+		Schema: b.pathAdminConfig()[1].Fields,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "request_retry_max_delay")
+
+	resp, err = b.pathConfigWrite(ctx, req, &framework.FieldData{
+		Raw: map[string]interface{}{
+			"admin_api_key":          TestAPIKey,
+			"admin_api_key_id":       TestAdminAPIKeyID,
+			"organization_id":        TestOrganizationID,
+			"verify_connection":      false,
+			"request_retry_attempts": 5,
+		},
+		Schema: b.pathAdminConfig()[1].Fields,
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = b.pathConfigRead(ctx, req, &framework.FieldData{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, resp.Data["request_retry_attempts"])
+	assert.Equal(t, int64(DefaultRequestRetryBaseDelay.Seconds()), resp.Data["request_retry_base_delay"])
+	assert.Equal(t, int64(DefaultRequestRetryMaxDelay.Seconds()), resp.Data["request_retry_max_delay"])
+
+	client := b.getClient(defaultConnectionName).(*Client)
+	assert.Equal(t, 5, client.retryPolicy.Attempts)
+}
+
+func TestConfigWrite_SerializedWithRotation(t *testing.T) {
+	b := getTestBackend(t)
+	offlineAdminKeyLookups(b)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	schema := b.pathAdminConfig()[1].Fields
+
+	write := func(raw map[string]interface{}) (*logical.Response, error) {
+		raw["verify_connection"] = false
+		return b.pathConfigWrite(ctx, &logical.Request{
+			Storage:    storage,
+			MountPoint: TestMountPoint,
+			Path:       TestConfigPath,
+		}, &framework.FieldData{Raw: raw, Schema: schema})
+	}
+
+	resp, err := write(map[string]interface{}{
+		"admin_api_key":    TestAPIKey,
+		"admin_api_key_id": TestAdminAPIKeyID,
+		"organization_id":  TestOrganizationID,
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	// Simulate a rotation holding the config lock while an operator updates
+	// an unrelated field.
+	lock := b.configLock(defaultConnectionName)
+	lock.Lock()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := write(map[string]interface{}{"organization_id": "org-updated"})
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		lock.Unlock()
+		t.Fatal("config write must wait for the in-flight rotation")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	cfg.AdminAPIKey = "sk-admin-rotated"
+	cfg.AdminAPIKeyID = "key_rotated"
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, cfg))
+	lock.Unlock()
+
+	require.NoError(t, <-done)
+
+	// The write applied on top of the rotation instead of clobbering it.
+	cfg, err = getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-admin-rotated", cfg.AdminAPIKey)
+	assert.Equal(t, "key_rotated", cfg.AdminAPIKeyID)
+	assert.Equal(t, "org-updated", cfg.OrganizationID)
+}
+
+func TestPutConnectionConfig_RejectsStaleVersion(t *testing.T) {
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, &openaiConfig{AdminAPIKey: "sk-admin-original"}))
+
+	first, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	stale, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+
+	first.AdminAPIKey = "sk-admin-rotated"
+	require.NoError(t, putConnectionConfig(ctx, storage, defaultConnectionName, first))
+	assert.Equal(t, uint64(2), first.Version)
+
+	stale.OrganizationID = "org-stale"
+	err = putConnectionConfig(ctx, storage, defaultConnectionName, stale)
+	require.ErrorIs(t, err, errConfigConflict)
+
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-admin-rotated", cfg.AdminAPIKey)
+	assert.Empty(t, cfg.OrganizationID)
+
+	// A stale config cannot resurrect a deleted connection either.
+	require.NoError(t, storage.Delete(ctx, configPath))
+	require.ErrorIs(t, putConnectionConfig(ctx, storage, defaultConnectionName, first), errConfigConflict)
+}
@@ -0,0 +1,217 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// reservedConnectionNames are names that cannot be used for a named
+// connection because they collide with the default connection or with other
+// paths under "config/".
+var reservedConnectionNames = map[string]bool{
+	defaultConnectionName: true,
+	"rotate":              true,
+	"health":              true,
+	"rotation-status":     true,
+}
+
+// pathConnections returns the paths for named OpenAI connections. Each named
+// connection has its own admin key, endpoint, organization and rotation job,
+// and is stored alongside the default connection under "config/<name>".
+func (b *backend) pathConnections() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: configPath + "/" + framework.GenericNameRegex("name") + "/rotate",
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "openai",
+				OperationVerb:   "rotate",
+				OperationSuffix: "connection-root-credentials",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the connection",
+					Required:    true,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:                    b.pathConfigRotateRoot,
+					ForwardPerformanceStandby:   true,
+					ForwardPerformanceSecondary: true,
+					Summary:                     "Rotate the admin API key of a named connection.",
+				},
+			},
+			HelpSynopsis:    "Rotate the admin API key of a named connection",
+			HelpDescription: "Rotates the admin API key used by a named connection. This creates a new admin API key and revokes the old one once rotation_overlap has elapsed.",
+		},
+		{
+			Pattern: configPath + "/" + framework.GenericNameRegex("name"),
+			Fields: func() map[string]*framework.FieldSchema {
+				fields := configFields()
+				fields["name"] = &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "Name of the connection",
+					Required:    true,
+				}
+				fields["force"] = &framework.FieldSchema{
+					Type:        framework.TypeBool,
+					Description: "Delete the connection even if roles use it. Those roles fail to issue credentials until they are given another connection or the connection is configured again.",
+					Query:       true,
+				}
+				return fields
+			}(),
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathConfigRead,
+					Summary:  "Read a named OpenAI connection.",
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathConnectionWrite,
+					Summary:  "Configure a named OpenAI connection.",
+				},
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathConnectionWrite,
+					Summary:  "Configure a named OpenAI connection.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.pathConnectionDelete,
+					Summary:  "Remove a named OpenAI connection.",
+				},
+			},
+			ExistenceCheck:  existenceCheckForNamedPath("name", connectionStoragePath),
+			HelpSynopsis:    connectionHelpSyn,
+			HelpDescription: connectionHelpDesc,
+		},
+		{
+			Pattern: configPath + "/?$",
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.pathConnectionList,
+					Summary:  "List configured OpenAI connections.",
+				},
+			},
+			HelpSynopsis:    connectionListHelpSyn,
+			HelpDescription: connectionListHelpDesc,
+		},
+	}
+}
+
+// pathConnectionWrite validates the connection name before delegating to the
+// shared config write handler.
+func (b *backend) pathConnectionWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if name == "" {
+		return logical.ErrorResponse("connection name is required"), nil
+	}
+	if reservedConnectionNames[name] {
+		return logical.ErrorResponse("connection name %q is reserved", name), nil
+	}
+	return b.pathConfigWrite(ctx, req, data)
+}
+
+// pathConnectionDelete validates the connection name before delegating to the
+// shared config delete handler, so "config/default" cannot remove "config".
+// A connection that roles use is only deleted with force.
+func (b *backend) pathConnectionDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if reservedConnectionNames[name] {
+		return logical.ErrorResponse("connection name %q is reserved", name), nil
+	}
+
+	roles, err := rolesUsingConnection(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) > 0 && !data.Get("force").(bool) {
+		return logical.ErrorResponse("connection %q is used by roles %s; give them another connection, or delete with force=true",
+			name, strings.Join(roles, ", ")), nil
+	}
+
+	resp, err := b.pathConfigDelete(ctx, req, data)
+	if err != nil || (resp != nil && resp.IsError()) || len(roles) == 0 {
+		return resp, err
+	}
+	if resp == nil {
+		resp = &logical.Response{}
+	}
+	resp.AddWarning(fmt.Sprintf("roles %s use the deleted connection and cannot issue credentials until it is configured again",
+		strings.Join(roles, ", ")))
+	return resp, nil
+}
+
+// rolesUsingConnection returns the names of the roles that use the named
+// connection.
+func rolesUsingConnection(ctx context.Context, storage logical.Storage, name string) ([]string, error) {
+	names, err := storage.List(ctx, roleStoragePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing roles: %w", err)
+	}
+	var roles []string
+	for _, roleName := range names {
+		role, _, err := readRole(ctx, storage, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if role != nil && role.connectionName() == name {
+			roles = append(roles, roleName)
+		}
+	}
+	return roles, nil
+}
+
+// pathConnectionList lists the configured connections, including the default
+// connection when "config" has been written.
+func (b *backend) pathConnectionList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	names, err := req.Storage.List(ctx, configPath+"/")
+	if err != nil {
+		return nil, fmt.Errorf("error listing connections: %w", err)
+	}
+
+	config, err := getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config != nil {
+		names = append([]string{defaultConnectionName}, names...)
+	}
+
+	return logical.ListResponse(names), nil
+}
+
+// connectionExists reports whether the named connection has been configured.
+func connectionExists(ctx context.Context, s logical.Storage, name string) (bool, error) {
+	config, err := getConnectionConfig(ctx, s, name)
+	if err != nil {
+		return false, err
+	}
+	return config != nil, nil
+}
+
+const connectionHelpSyn = `
+Configure a named OpenAI connection.
+`
+
+const connectionHelpDesc = `
+This endpoint configures an additional OpenAI connection with its own Admin API
+key, API endpoint, organization ID and rotation schedule. Roles select the
+connection to use with their "connection" parameter. The connection stored at
+"config" is available to roles as "default". A connection that roles use is
+only deleted with force=true.
+`
+
+const connectionListHelpSyn = `
+List configured OpenAI connections.
+`
+
+const connectionListHelpDesc = `
+This endpoint lists the configured OpenAI connections. The connection stored at
+"config" is listed as "default".
+`
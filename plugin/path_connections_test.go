@@ -0,0 +1,258 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionNameFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"config", defaultConnectionName},
+		{"config/rotate", defaultConnectionName},
+		{"config/prod", "prod"},
+		{"config/prod/rotate", "prod"},
+		{"config/rotate-keys", "rotate-keys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, connectionNameFromPath(tt.path))
+		})
+	}
+}
+
+func TestConnections_CRUD(t *testing.T) {
+	b := getTestBackend(t)
+	offlineAdminKeyLookups(b)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	schema := b.pathConnections()[1].Fields
+
+	writeConnection := func(name, org string) *logical.Response {
+		resp, err := b.pathConnectionWrite(ctx, &logical.Request{
+			Storage:    storage,
+			MountPoint: TestMountPoint,
+			Path:       "config/" + name,
+		}, &framework.FieldData{
+			Raw: map[string]interface{}{
+				"name":              name,
+				"admin_api_key":     "sk-admin-" + name,
+				"admin_api_key_id":  "key_" + name,
+				"organization_id":   org,
+				"verify_connection": false,
+			},
+			Schema: schema,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	require.Nil(t, writeConnection("prod", "org-prod"))
+	require.Nil(t, writeConnection("research", "org-research"))
+
+	// Connections are stored independently of the default config.
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	prod, err := getConnectionConfig(ctx, storage, "prod")
+	require.NoError(t, err)
+	require.NotNil(t, prod)
+	assert.Equal(t, "org-prod", prod.OrganizationID)
+	assert.Equal(t, "sk-admin-prod", prod.AdminAPIKey)
+
+	// Each connection gets its own cached client.
+	assert.NotNil(t, b.getClient("prod"))
+	assert.NotNil(t, b.getClient("research"))
+	assert.NotSame(t, b.getClient("prod"), b.getClient("research"))
+
+	resp, err := b.pathConfigRead(ctx, &logical.Request{Storage: storage}, &framework.FieldData{
+		Raw:    map[string]interface{}{"name": "research"},
+		Schema: schema,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, "org-research", resp.Data["organization_id"])
+	assert.NotContains(t, resp.Data, "admin_api_key")
+
+	resp, err = b.pathConnectionList(ctx, &logical.Request{Storage: storage}, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"prod", "research"}, resp.Data["keys"])
+
+	// Delete one connection; the other is untouched.
+	resp, err = b.pathConnectionDelete(ctx, &logical.Request{
+		Storage:    storage,
+		MountPoint: TestMountPoint,
+		Path:       "config/prod",
+	}, &framework.FieldData{Raw: map[string]interface{}{"name": "prod"}, Schema: schema})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	prod, err = getConnectionConfig(ctx, storage, "prod")
+	require.NoError(t, err)
+	assert.Nil(t, prod)
+	assert.Nil(t, b.getClient("prod"))
+	assert.NotNil(t, b.getClient("research"))
+}
+
+func TestConnections_ReservedNames(t *testing.T) {
+	b := getTestBackend(t)
+	schema := b.pathConnections()[1].Fields
+
+	for _, name := range []string{defaultConnectionName, "rotate", "rotation-status", "health"} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := b.pathConnectionWrite(context.Background(), &logical.Request{
+				Storage:    &logical.InmemStorage{},
+				MountPoint: TestMountPoint,
+				Path:       "config/" + name,
+			}, &framework.FieldData{
+				Raw: map[string]interface{}{
+					"name":             name,
+					"admin_api_key":    "sk-admin-x",
+					"admin_api_key_id": "key_x",
+					"organization_id":  TestOrganizationID,
+				},
+				Schema: schema,
+			})
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+			assert.True(t, resp.IsError())
+		})
+	}
+}
+
+func TestConnections_RoleUsesConnectionClient(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	// Store a named connection and route it to its own mock client.
+	entry, err := logical.StorageEntryJSON(connectionStoragePath("research"), &openaiConfig{
+		AdminAPIKey:    "sk-admin-research",
+		AdminAPIKeyID:  "key_research",
+		OrganizationID: "org-research",
+		APIEndpoint:    DefaultAPIEndpoint,
+	})
+	require.NoError(t, err)
+	require.NoError(t, storage.Put(ctx, entry))
+
+	var createdIn, deletedIn string
+	b.setClient("research", &mockClient{
+		createServiceAccountFn: func(_ context.Context, projectID string, req CreateServiceAccountRequest) (*ServiceAccount, *APIKey, error) {
+			createdIn = "research"
+			return &ServiceAccount{ID: "svc-research", Name: req.Name, ProjectID: projectID},
+				&APIKey{ID: "key-research", Value: "sk-research"}, nil
+		},
+		deleteServiceAccountFn: func(_ context.Context, id string, _ ...string) error {
+			deletedIn = "research"
+			return nil
+		},
+	})
+
+	roleSchema := b.pathDynamicSvcAccount()[0].Fields
+
+	// A role cannot reference a connection that does not exist.
+	resp, err := b.pathRoleWrite(ctx, &logical.Request{Storage: storage}, &framework.FieldData{
+		Raw:    map[string]interface{}{"name": "bad", "project_id": TestProjectID, "connection": "missing"},
+		Schema: roleSchema,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+
+	resp, err = b.pathRoleWrite(ctx, &logical.Request{Storage: storage}, &framework.FieldData{
+		Raw:    map[string]interface{}{"name": "analytics", "project_id": TestProjectID, "connection": "research"},
+		Schema: roleSchema,
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = b.pathRoleRead(ctx, &logical.Request{Storage: storage}, &framework.FieldData{
+		Raw:    map[string]interface{}{"name": "analytics"},
+		Schema: roleSchema,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "research", resp.Data["connection"])
+
+	resp, err = b.pathCredsCreate(ctx, &logical.Request{Storage: storage}, &framework.FieldData{
+		Raw:    map[string]interface{}{"name": "analytics"},
+		Schema: b.pathDynamicCredsCreate()[0].Fields,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, "research", createdIn)
+	assert.Equal(t, "research", resp.Secret.InternalData["connection"])
+
+	_, err = b.dynamicCredsRevoke(ctx, &logical.Request{
+		Storage: storage,
+		Secret:  &logical.Secret{InternalData: resp.Secret.InternalData},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "research", deletedIn)
+}
+
+func TestConnections_DeleteInUse(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	entry, err := logical.StorageEntryJSON(connectionStoragePath("research"), &openaiConfig{
+		AdminAPIKey:    "sk-admin-research",
+		AdminAPIKeyID:  "key_research",
+		OrganizationID: "org-research",
+		APIEndpoint:    DefaultAPIEndpoint,
+	})
+	require.NoError(t, err)
+	require.NoError(t, storage.Put(ctx, entry))
+	b.setClient("research", &mockClient{})
+
+	roleSchema := b.pathDynamicSvcAccount()[0].Fields
+	for _, role := range []string{"analytics", "batch"} {
+		resp, err := b.pathRoleWrite(ctx, &logical.Request{Storage: storage}, &framework.FieldData{
+			Raw:    map[string]interface{}{"name": role, "project_id": TestProjectID, "connection": "research"},
+			Schema: roleSchema,
+		})
+		require.NoError(t, err)
+		require.Nil(t, resp)
+	}
+
+	deleteConnection := func(raw map[string]interface{}) *logical.Response {
+		raw["name"] = "research"
+		resp, err := b.pathConnectionDelete(ctx, &logical.Request{
+			Storage:    storage,
+			MountPoint: TestMountPoint,
+			Path:       "config/research",
+		}, &framework.FieldData{Raw: raw, Schema: b.pathConnections()[1].Fields})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := deleteConnection(map[string]interface{}{})
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "analytics, batch")
+	assert.Contains(t, resp.Error().Error(), "force=true")
+	exists, err := connectionExists(ctx, storage, "research")
+	require.NoError(t, err)
+	assert.True(t, exists, "a connection in use is kept")
+
+	resp = deleteConnection(map[string]interface{}{"force": true})
+	require.NotNil(t, resp)
+	assert.False(t, resp.IsError())
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "analytics, batch")
+	exists, err = connectionExists(ctx, storage, "research")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
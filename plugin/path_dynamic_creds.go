@@ -6,7 +6,9 @@ package openaisecrets
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
@@ -30,6 +32,11 @@ func (b *backend) pathDynamicSvcAccount() []*framework.Path {
 					Description: "OpenAI Project ID to use for this role (e.g., proj_abc123)",
 					Required:    true,
 				},
+				"connection": {
+					Type:        framework.TypeString,
+					Description: "Name of the OpenAI connection to use for this role. Defaults to the connection configured at config.",
+					Default:     defaultConnectionName,
+				},
 				"service_account_name_template": {
 					Type:        framework.TypeString,
 					Description: "Template for the service account name to be created",
@@ -118,13 +125,28 @@ func (b *backend) pathDynamicCredsCreate() []*framework.Path {
 	}
 }
 
+const (
+	// roleStoragePrefix is the storage prefix of role entries.
+	roleStoragePrefix = "roles/"
+
+	// Defaults for role fields that are not set.
+	defaultServiceAccountNameTemplate = "vault-{{.RoleName}}-{{.RandomSuffix}}"
+	defaultServiceAccountDescription  = "Service account created by Vault"
+	defaultRoleTTL                    = time.Hour
+	defaultRoleMaxTTL                 = 24 * time.Hour
+)
+
 // dynamicRoleEntry represents a dynamic role
 type dynamicRoleEntry struct {
+	Connection                 string        `json:"connection,omitempty"`
 	ProjectID                  string        `json:"project_id"`
 	ServiceAccountNameTemplate string        `json:"service_account_name_template"`
 	ServiceAccountDescription  string        `json:"service_account_description"`
 	TTL                        time.Duration `json:"ttl"`
 	MaxTTL                     time.Duration `json:"max_ttl"`
+
+	// SchemaVersion is the storage schema version; see upgradeRole.
+	SchemaVersion int `json:"schema_version"`
 }
 
 // pathRoleRead reads a role definition
@@ -145,6 +167,7 @@ func (b *backend) pathRoleRead(ctx context.Context, req *logical.Request, data *
 	// Return role information
 	return &logical.Response{
 		Data: map[string]interface{}{
+			"connection":                    role.connectionName(),
 			"project_id":                    role.ProjectID,
 			"service_account_name_template": role.ServiceAccountNameTemplate,
 			"service_account_description":   role.ServiceAccountDescription,
@@ -167,17 +190,33 @@ func (b *backend) pathRoleWrite(ctx context.Context, req *logical.Request, data
 		return nil, err
 	}
 	if role == nil {
-		role = &dynamicRoleEntry{}
+		role = &dynamicRoleEntry{SchemaVersion: roleSchemaVersion}
 	}
 
 	// Update role from request data
+	if connection, ok := data.GetOk("connection"); ok {
+		role.Connection = connection.(string)
+	}
+	if role.Connection == "" {
+		role.Connection = defaultConnectionName
+	}
+	if role.Connection != defaultConnectionName {
+		exists, err := connectionExists(ctx, req.Storage, role.Connection)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return logical.ErrorResponse("connection %q is not configured", role.Connection), nil
+		}
+	}
+
 	projectID := data.Get("project_id").(string)
 	if projectID == "" {
 		return logical.ErrorResponse("project_id is required"), nil
 	}
 
 	// Verify the project exists and is active
-	projectInfo, err := b.validateProject(ctx, req.Storage, projectID)
+	projectInfo, err := b.validateProject(ctx, req.Storage, role.Connection, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("error validating project: %w", err)
 	}
@@ -194,25 +233,25 @@ func (b *backend) pathRoleWrite(ctx context.Context, req *logical.Request, data
 		}
 		role.ServiceAccountNameTemplate = tmplStr
 	} else if role.ServiceAccountNameTemplate == "" {
-		role.ServiceAccountNameTemplate = "vault-{{.RoleName}}-{{.RandomSuffix}}"
+		role.ServiceAccountNameTemplate = defaultServiceAccountNameTemplate
 	}
 
 	if serviceAccountDescription, ok := data.GetOk("service_account_description"); ok {
 		role.ServiceAccountDescription = serviceAccountDescription.(string)
 	} else if role.ServiceAccountDescription == "" {
-		role.ServiceAccountDescription = "Service account created by Vault"
+		role.ServiceAccountDescription = defaultServiceAccountDescription
 	}
 
 	if ttlRaw, ok := data.GetOk("ttl"); ok {
 		role.TTL = time.Duration(ttlRaw.(int)) * time.Second
 	} else if role.TTL == 0 {
-		role.TTL = time.Hour // Default 1 hour
+		role.TTL = defaultRoleTTL
 	}
 
 	if maxTTLRaw, ok := data.GetOk("max_ttl"); ok {
 		role.MaxTTL = time.Duration(maxTTLRaw.(int)) * time.Second
 	} else if role.MaxTTL == 0 {
-		role.MaxTTL = 24 * time.Hour // Default 24 hours
+		role.MaxTTL = defaultRoleMaxTTL
 	}
 
 	// Validate TTLs
@@ -221,11 +260,7 @@ func (b *backend) pathRoleWrite(ctx context.Context, req *logical.Request, data
 	}
 
 	// Save role
-	entry, err := logical.StorageEntryJSON(roleStoragePath(roleName), role)
-	if err != nil {
-		return nil, err
-	}
-	if err := req.Storage.Put(ctx, entry); err != nil {
+	if err := putRole(ctx, req.Storage, roleName, role); err != nil {
 		return nil, err
 	}
 
@@ -249,7 +284,7 @@ func (b *backend) pathRoleDelete(ctx context.Context, req *logical.Request, data
 
 // pathRoleList lists all role definitions
 func (b *backend) pathRoleList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	roles, err := req.Storage.List(ctx, "roles/")
+	roles, err := req.Storage.List(ctx, roleStoragePrefix)
 	if err != nil {
 		return nil, fmt.Errorf("error listing roles: %w", err)
 	}
@@ -263,30 +298,58 @@ func (b *backend) getRole(ctx context.Context, s logical.Storage, name string) (
 		return nil, fmt.Errorf("role name is required")
 	}
 
+	role, _, err := readRole(ctx, s, name)
+	return role, err
+}
+
+// readRole returns a role upgraded to the current schema version, and whether
+// an upgrade was applied.
+func readRole(ctx context.Context, s logical.Storage, name string) (*dynamicRoleEntry, bool, error) {
 	entry, err := s.Get(ctx, roleStoragePath(name))
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving role: %w", err)
+		return nil, false, fmt.Errorf("error retrieving role: %w", err)
 	}
 	if entry == nil {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	var role dynamicRoleEntry
 	if err := entry.DecodeJSON(&role); err != nil {
-		return nil, fmt.Errorf("error decoding role: %w", err)
+		return nil, false, fmt.Errorf("error decoding role: %w", err)
+	}
+
+	return &role, upgradeRole(&role), nil
+}
+
+// putRole saves a role.
+func putRole(ctx context.Context, s logical.Storage, name string, role *dynamicRoleEntry) error {
+	entry, err := logical.StorageEntryJSON(roleStoragePath(name), role)
+	if err != nil {
+		return err
 	}
+	return s.Put(ctx, entry)
+}
 
-	return &role, nil
+// connectionName returns the connection the role uses. Roles written before
+// named connections existed have no connection and use the default one.
+func (r *dynamicRoleEntry) connectionName() string {
+	if r.Connection == "" {
+		return defaultConnectionName
+	}
+	return r.Connection
 }
 
 // roleStoragePath returns the storage path for a role
 func roleStoragePath(name string) string {
-	return fmt.Sprintf("roles/%s", name)
+	return roleStoragePrefix + name
 }
 
 // pathCredsCreate creates dynamic credentials for a role
-func (b *backend) pathCredsCreate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+func (b *backend) pathCredsCreate(ctx context.Context, req *logical.Request, data *framework.FieldData) (resp *logical.Response, err error) {
 	roleName := data.Get("name").(string)
+	ctx, span := b.startSpan(ctx, "openai.creds.create", attrRole.String(roleName))
+	defer func() { endSpan(span, resp, err) }()
+
 	if roleName == "" {
 		return logical.ErrorResponse("role name is required"), nil
 	}
@@ -300,8 +363,11 @@ func (b *backend) pathCredsCreate(ctx context.Context, req *logical.Request, dat
 		return logical.ErrorResponse("role %q does not exist", roleName), nil
 	}
 
+	connection := role.connectionName()
+	span.SetAttributes(attrConnection.String(connection))
+
 	// Validate project is still active
-	projectInfo, err := b.validateProject(ctx, req.Storage, role.ProjectID)
+	projectInfo, err := b.validateProject(ctx, req.Storage, connection, role.ProjectID)
 	if err != nil {
 		return nil, fmt.Errorf("error validating project: %w", err)
 	}
@@ -309,7 +375,7 @@ func (b *backend) pathCredsCreate(ctx context.Context, req *logical.Request, dat
 		return logical.ErrorResponse("project_id %q does not exist", role.ProjectID), nil
 	}
 
-	client, err := b.configuredClient(ctx, req.Storage)
+	client, err := b.configuredClient(ctx, req.Storage, connection)
 	if err != nil {
 		return logical.ErrorResponse("OpenAI configuration error: %s", err.Error()), nil
 	}
@@ -362,7 +428,7 @@ func (b *backend) pathCredsCreate(ctx context.Context, req *logical.Request, dat
 	// service accounts; Vault's lease TTL is used for revocation scheduling.
 
 	// Generate the response
-	resp := b.Secret(dynamicSecretCredsType).Response(map[string]interface{}{
+	resp = b.Secret(dynamicSecretCredsType).Response(map[string]interface{}{
 		"api_key":            apiKey.Value,
 		"api_key_id":         apiKey.ID,
 		"service_account_id": svcAccount.ID,
@@ -371,6 +437,9 @@ func (b *backend) pathCredsCreate(ctx context.Context, req *logical.Request, dat
 		"api_key_id":         apiKey.ID,
 		"service_account_id": svcAccount.ID,
 		"project_id":         projectInfo.ID,
+		"connection":         connection,
+		"role":               roleName,
+		"schema_version":     leaseSchemaVersion,
 	})
 
 	// Set lease
@@ -408,7 +477,10 @@ func dynamicSecretCreds(b *backend) *framework.Secret {
 }
 
 // dynamicCredsRevoke revokes the API key and deletes the service account
-func (b *backend) dynamicCredsRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+func (b *backend) dynamicCredsRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (resp *logical.Response, err error) {
+	ctx, span := b.startSpan(ctx, "openai.creds.revoke")
+	defer func() { endSpan(span, resp, err) }()
+
 	apiKeyID, ok := req.Secret.InternalData["api_key_id"].(string)
 	if !ok || apiKeyID == "" {
 		return nil, fmt.Errorf("internal error: api_key_id missing or not a string in lease internal data")
@@ -417,26 +489,68 @@ func (b *backend) dynamicCredsRevoke(ctx context.Context, req *logical.Request,
 	if !ok || serviceAccountID == "" {
 		return nil, fmt.Errorf("internal error: service_account_id missing or not a string in lease internal data")
 	}
-	projectID, ok := req.Secret.InternalData["project_id"].(string)
-	if !ok || projectID == "" {
-		return nil, fmt.Errorf("internal error: project_id missing or not a string in lease internal data")
+	projectID, _ := req.Secret.InternalData["project_id"].(string)
+	connection, _ := req.Secret.InternalData["connection"].(string)
+
+	// Leases issued by early plugin versions carry no project_id. Recover it,
+	// and the connection, from the role that issued the lease so these leases
+	// can still be revoked.
+	if projectID == "" {
+		role, err := b.leaseRole(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil || role.ProjectID == "" {
+			return nil, fmt.Errorf("internal error: project_id missing or not a string in lease internal data, and the issuing role could not be found")
+		}
+		b.Logger().Info("Revoking legacy lease using the project of its role", "service_account_id", serviceAccountID, "project_id", role.ProjectID)
+		projectID = role.ProjectID
+		if connection == "" {
+			connection = role.connectionName()
+		}
 	}
 
-	b.Logger().Debug("revoking API key for service Account", "service_account_id", serviceAccountID)
+	// Leases issued before named connections existed carry no connection and
+	// belong to the default one.
+	if connection == "" {
+		connection = defaultConnectionName
+	}
 
-	client, err := b.configuredClient(ctx, req.Storage)
+	b.Logger().Debug("revoking API key for service Account", "service_account_id", serviceAccountID, "connection", connection)
+	span.SetAttributes(attrConnection.String(connection))
+
+	client, err := b.configuredClient(ctx, req.Storage, connection)
 	if err != nil {
 		return logical.ErrorResponse("OpenAI configuration error: %s", err.Error()), nil
 	}
 
-	// Delete the service account
+	// Delete the service account. One that is already gone, for example
+	// deleted by hand in the OpenAI console, counts as revoked.
 	if err := client.DeleteServiceAccount(ctx, serviceAccountID, projectID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			b.Logger().Info("Service account was already deleted", "service_account_id", serviceAccountID, "project_id", projectID)
+			return nil, nil
+		}
 		return nil, fmt.Errorf("error deleting service account: %w", err)
 	}
 
 	return nil, nil
 }
 
+// leaseRole returns the role that issued the lease being revoked, or nil if
+// it cannot be determined. Leases record the role from schema version 1; for
+// older leases it is taken from the "creds/<role>" path that issued them.
+func (b *backend) leaseRole(ctx context.Context, req *logical.Request) (*dynamicRoleEntry, error) {
+	roleName, _ := req.Secret.InternalData["role"].(string)
+	if roleName == "" {
+		roleName = strings.TrimPrefix(req.Path, "creds/")
+		if roleName == req.Path || roleName == "" || strings.Contains(roleName, "/") {
+			return nil, nil
+		}
+	}
+	return b.getRole(ctx, req.Storage, roleName)
+}
+
 // Helper functions
 
 // formatName formats a name template with Vault's username templating helper.
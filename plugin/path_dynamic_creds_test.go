@@ -5,6 +5,7 @@ package openaisecrets
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -114,9 +115,7 @@ func TestGenerateRandomString(t *testing.T) {
 }
 
 func TestDynamicRoleEntry_Validation(t *testing.T) {
-	b := &backend{
-		client: nil, // Not needed for this test
-	}
+	b := &backend{}
 
 	// Setup storage - no longer need to cache projects
 	storage := &logical.InmemStorage{}
@@ -124,7 +123,7 @@ func TestDynamicRoleEntry_Validation(t *testing.T) {
 
 	// Configure mock client to handle project validation
 	mockClient := &mockClient{}
-	b.client = mockClient
+	b.setClient(defaultConnectionName, mockClient)
 
 	// Test cases
 	tests := []struct {
@@ -209,3 +208,37 @@ func TestDynamicRoleEntry_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestDynamicCredsRevoke_AlreadyDeleted(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	var deleteErr error
+	b.setClient(defaultConnectionName, &mockClient{
+		deleteServiceAccountFn: func(context.Context, string, ...string) error {
+			return deleteErr
+		},
+	})
+	revoke := func() error {
+		_, err := b.dynamicCredsRevoke(ctx, &logical.Request{
+			Storage: storage,
+			Secret: &logical.Secret{InternalData: map[string]interface{}{
+				"api_key_id":         "key-123",
+				"service_account_id": "svc-123",
+				"project_id":         TestProjectID,
+			}},
+		}, nil)
+		return err
+	}
+
+	// A service account deleted outside Vault is treated as revoked.
+	deleteErr = fmt.Errorf("error deleting service account: %w", &APIError{StatusCode: 404, Message: "not found"})
+	require.NoError(t, revoke())
+
+	// Other failures still fail the revocation so Vault retries it.
+	deleteErr = fmt.Errorf("error deleting service account: %w", &APIError{StatusCode: 500})
+	err := revoke()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "500")
+}
@@ -0,0 +1,143 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	healthStatusHealthy   = "healthy"
+	healthStatusUnhealthy = "unhealthy"
+)
+
+// pathHealth returns the admin key health paths for the default and named
+// connections.
+func (b *backend) pathHealth() []*framework.Path {
+	healthPath := func(pattern string, fields map[string]*framework.FieldSchema, suffix string) *framework.Path {
+		return &framework.Path{
+			Pattern: pattern,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "openai",
+				OperationVerb:   "read",
+				OperationSuffix: suffix,
+			},
+			Fields: fields,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathHealthRead,
+					Summary:  "Check the health of the configured admin API key.",
+				},
+			},
+			HelpSynopsis:    healthHelpSyn,
+			HelpDescription: healthHelpDesc,
+		}
+	}
+
+	return []*framework.Path{
+		healthPath(configPath+"/health", nil, "health"),
+		healthPath(configPath+"/"+framework.GenericNameRegex("name")+"/health", map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the connection",
+				Required:    true,
+			},
+		}, "connection-health"),
+	}
+}
+
+// pathHealthRead looks up the connection's admin key in OpenAI and reports
+// its details. Problems reaching OpenAI or with the key are returned as an
+// unhealthy status rather than a request error, so monitoring can read them.
+func (b *backend) pathHealthRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := connectionName(data)
+
+	config, err := getConnectionConfig(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	respData := map[string]interface{}{
+		"connection":       name,
+		"status":           healthStatusHealthy,
+		"checked_at":       time.Now().Format(time.RFC3339),
+		"admin_api_key_id": config.AdminAPIKeyID,
+	}
+
+	// The circuit breaker is shared by every client of the connection. The
+	// check below uses a breaker of its own, so it still reaches OpenAI
+	// while the connection's breaker is open.
+	respData["circuit_breaker"] = breakerStatusResponse(b.breakers.status(name))
+	unhealthy := func(err error) (*logical.Response, error) {
+		respData["status"] = healthStatusUnhealthy
+		respData["error"] = err.Error()
+		return &logical.Response{Data: respData}, nil
+	}
+
+	if config.AdminAPIKeyID == "" {
+		return unhealthy(fmt.Errorf("admin_api_key_id is not set"))
+	}
+
+	clientConfig := config.clientConfig(name)
+	clientConfig.ownBreaker = true
+	client, err := b.newClient(clientConfig, b.Logger())
+	if err != nil {
+		return unhealthy(fmt.Errorf("error configuring OpenAI client: %w", err))
+	}
+
+	key, err := client.GetAdminAPIKey(ctx, config.AdminAPIKeyID)
+	if err != nil {
+		return unhealthy(err)
+	}
+
+	respData["admin_api_key_name"] = key.Name
+	respData["admin_api_key_redacted_value"] = key.RedactedValue
+	respData["admin_api_key_created_at"] = formatUnixTimestamp(key.CreatedAt)
+	respData["admin_api_key_last_used_at"] = formatUnixTimestamp(key.LastUsedAt)
+
+	if redacted := key.RedactedValue; redacted != "" && !matchesRedactedValue(config.AdminAPIKey, redacted) {
+		return unhealthy(fmt.Errorf("admin_api_key_id %q does not match the configured admin_api_key", config.AdminAPIKeyID))
+	}
+
+	return &logical.Response{Data: respData}, nil
+}
+
+// breakerStatusResponse formats a circuit breaker status for the health
+// response.
+func breakerStatusResponse(status breakerStatus) map[string]interface{} {
+	resp := map[string]interface{}{
+		"state":                status.State,
+		"consecutive_failures": status.ConsecutiveFailures,
+	}
+	if status.LastError != "" {
+		resp["last_error"] = status.LastError
+	}
+	if !status.OpenedAt.IsZero() {
+		resp["opened_at"] = status.OpenedAt.Format(time.RFC3339)
+		resp["retry_at"] = status.RetryAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+const healthHelpSyn = `
+Check the health of a connection's admin API key.
+`
+
+const healthHelpDesc = `
+This endpoint looks up the configured admin API key in OpenAI and returns its
+name, redacted value, creation time and last use time. If OpenAI cannot be
+reached, or the key is invalid, revoked or does not match admin_api_key_id,
+the response has status "unhealthy" and an "error" describing the problem.
+
+The response also reports the state of the connection's circuit breaker,
+which fails requests fast while OpenAI is unavailable.
+`
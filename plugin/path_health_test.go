@@ -0,0 +1,95 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readHealth(t *testing.T, b *backend, storage logical.Storage, path string) *logical.Response {
+	t.Helper()
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      path,
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestHealth_DefaultConnection(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+
+	// Unconfigured connections have no health.
+	assert.Nil(t, readHealth(t, b, storage, "config/health"))
+
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+
+	resp := readHealth(t, b, storage, "config/health")
+	require.NotNil(t, resp)
+	assert.Equal(t, healthStatusHealthy, resp.Data["status"])
+	assert.Equal(t, TestAdminAPIKeyID, resp.Data["admin_api_key_id"])
+	assert.Equal(t, "sample-admin-key", resp.Data["admin_api_key_name"])
+	assert.Equal(t, redactMockKey(TestAPIKey), resp.Data["admin_api_key_redacted_value"])
+	assert.NotEmpty(t, resp.Data["admin_api_key_created_at"])
+	assert.NotEmpty(t, resp.Data["admin_api_key_last_used_at"])
+	assert.NotContains(t, resp.Data, "error")
+
+	// A key revoked outside Vault is reported as unhealthy, not as an error.
+	mockServer.mutex.Lock()
+	delete(mockServer.adminKeys, TestAdminAPIKeyID)
+	mockServer.mutex.Unlock()
+
+	resp = readHealth(t, b, storage, "config/health")
+	require.NotNil(t, resp)
+	assert.False(t, resp.IsError())
+	assert.Equal(t, healthStatusUnhealthy, resp.Data["status"])
+	assert.NotEmpty(t, resp.Data["error"])
+}
+
+func TestHealth_KeyIDMismatch(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+	mockServer.AddAdminAPIKey("key_other", "other-admin-key", "sk-admin-other-value")
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	require.NoError(t, putConnectionConfig(ctx, storage, "prod", &openaiConfig{
+		AdminAPIKey:    TestAPIKey,
+		AdminAPIKeyID:  "key_other",
+		OrganizationID: TestOrganizationID,
+		APIEndpoint:    mockServer.URL() + "/v1",
+	}))
+
+	resp := readHealth(t, b, storage, "config/prod/health")
+	require.NotNil(t, resp)
+	assert.Equal(t, "prod", resp.Data["connection"])
+	assert.Equal(t, healthStatusUnhealthy, resp.Data["status"])
+	assert.Contains(t, resp.Data["error"], "does not match")
+	assert.Equal(t, "other-admin-key", resp.Data["admin_api_key_name"])
+}
+
+func TestFormatUnixTimestamp(t *testing.T) {
+	unixTime := func(t time.Time) *UnixTime {
+		ut := UnixTime(t)
+		return &ut
+	}
+	assert.Equal(t, "2023-11-14T22:13:20Z", formatUnixTimestamp(unixTime(time.Unix(1700000000, 0))))
+	assert.Equal(t, "2024-01-01T00:00:00Z", formatUnixTimestamp(unixTime(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))))
+	assert.Equal(t, "", formatUnixTimestamp(nil))
+	assert.Equal(t, "", formatUnixTimestamp(unixTime(time.Time{})))
+	assert.Equal(t, "", formatUnixTimestamp(unixTime(time.Unix(0, 0))))
+}
@@ -0,0 +1,249 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/rotation"
+)
+
+const (
+	// rotationStatusStoragePrefix holds one rotation status entry per
+	// connection. It lives outside "config/" so it is never listed as a
+	// connection.
+	rotationStatusStoragePrefix = "rotation-status/"
+
+	// maxRotationHistory bounds the number of rotation outcomes kept per
+	// connection.
+	maxRotationHistory = 20
+
+	// Rotation triggers recorded in the rotation history.
+	rotationTriggerManual      = "manual"
+	rotationTriggerScheduled   = "scheduled"
+	rotationTriggerConfigWrite = "config_write"
+)
+
+// rotationOutcome records a single admin key rotation attempt.
+type rotationOutcome struct {
+	Time             time.Time `json:"time"`
+	Trigger          string    `json:"trigger"`
+	Success          bool      `json:"success"`
+	Error            string    `json:"error,omitempty"`
+	OldAdminAPIKeyID string    `json:"old_admin_api_key_id,omitempty"`
+	NewAdminAPIKeyID string    `json:"new_admin_api_key_id,omitempty"`
+}
+
+// rotationStatus is the stored rotation status of a connection. History is
+// ordered oldest first.
+type rotationStatus struct {
+	LastAttempt   time.Time         `json:"last_attempt"`
+	LastSuccess   time.Time         `json:"last_success"`
+	LastError     string            `json:"last_error,omitempty"`
+	LastErrorTime time.Time         `json:"last_error_time"`
+	History       []rotationOutcome `json:"history,omitempty"`
+}
+
+// rotationStatusStoragePath returns the storage path of a connection's
+// rotation status.
+func rotationStatusStoragePath(name string) string {
+	if name == "" {
+		name = defaultConnectionName
+	}
+	return rotationStatusStoragePrefix + name
+}
+
+// pathRotationStatus returns the rotation status paths for the default and
+// named connections. The "detailed" variants also return the admin key IDs
+// involved in each rotation, so they can be restricted to privileged callers
+// by policy.
+func (b *backend) pathRotationStatus() []*framework.Path {
+	nameField := map[string]*framework.FieldSchema{
+		"name": {
+			Type:        framework.TypeString,
+			Description: "Name of the connection",
+			Required:    true,
+		},
+	}
+
+	statusPath := func(pattern string, fields map[string]*framework.FieldSchema, detailed bool) *framework.Path {
+		suffix := "rotation-status"
+		if detailed {
+			suffix = "detailed-rotation-status"
+		}
+		return &framework.Path{
+			Pattern: pattern,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "openai",
+				OperationVerb:   "read",
+				OperationSuffix: suffix,
+			},
+			Fields: fields,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+						return b.pathRotationStatusRead(ctx, req, data, detailed)
+					},
+					Summary: "Read the admin API key rotation status and history.",
+				},
+			},
+			HelpSynopsis:    rotationStatusHelpSyn,
+			HelpDescription: rotationStatusHelpDesc,
+		}
+	}
+
+	return []*framework.Path{
+		statusPath(configPath+"/rotation-status", nil, false),
+		statusPath(configPath+"/rotation-status/detailed", nil, true),
+		statusPath(configPath+"/"+framework.GenericNameRegex("name")+"/rotation-status", nameField, false),
+		statusPath(configPath+"/"+framework.GenericNameRegex("name")+"/rotation-status/detailed", nameField, true),
+	}
+}
+
+// pathRotationStatusRead returns the rotation status of a connection. Admin
+// key IDs are only included when detailed is set.
+func (b *backend) pathRotationStatusRead(ctx context.Context, req *logical.Request, data *framework.FieldData, detailed bool) (*logical.Response, error) {
+	name := connectionName(data)
+
+	config, err := getConnectionConfig(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	status, err := getRotationStatus(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	respData := map[string]interface{}{
+		"connection":      name,
+		"last_attempt":    formatOptionalTime(status.LastAttempt),
+		"last_success":    formatOptionalTime(status.LastSuccess),
+		"last_error":      status.LastError,
+		"last_error_time": formatOptionalTime(status.LastErrorTime),
+	}
+
+	// The rotation manager is only consulted for connections with a
+	// registered rotation job, and not every Vault edition reports schedules.
+	if config.ShouldRegisterRotationJob() {
+		info, err := b.System().GetRotationInformation(ctx, &rotation.RotationInfoRequest{
+			ReqPath: connectionStoragePath(name),
+		})
+		if err != nil {
+			b.Logger().Debug("Rotation manager did not report rotation information", "connection", name, "error", err)
+		} else if info != nil && !info.NextVaultRotation.IsZero() {
+			respData["next_scheduled_rotation"] = info.NextVaultRotation.Format(time.RFC3339)
+		}
+	}
+
+	history := make([]map[string]interface{}, 0, len(status.History))
+	for i := len(status.History) - 1; i >= 0; i-- {
+		outcome := status.History[i]
+		entry := map[string]interface{}{
+			"time":    outcome.Time.Format(time.RFC3339),
+			"trigger": outcome.Trigger,
+			"success": outcome.Success,
+		}
+		if outcome.Error != "" {
+			entry["error"] = outcome.Error
+		}
+		if detailed {
+			entry["old_admin_api_key_id"] = outcome.OldAdminAPIKeyID
+			entry["new_admin_api_key_id"] = outcome.NewAdminAPIKeyID
+		}
+		history = append(history, entry)
+	}
+	respData["history"] = history
+
+	return &logical.Response{Data: respData}, nil
+}
+
+// getRotationStatus returns the stored rotation status of a connection, or an
+// empty status if none has been recorded.
+func getRotationStatus(ctx context.Context, s logical.Storage, name string) (*rotationStatus, error) {
+	entry, err := s.Get(ctx, rotationStatusStoragePath(name))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &rotationStatus{}
+	if entry == nil {
+		return status, nil
+	}
+	if err := entry.DecodeJSON(status); err != nil {
+		return nil, fmt.Errorf("error reading rotation status: %w", err)
+	}
+	return status, nil
+}
+
+// recordRotationOutcome adds a rotation outcome to the connection's status.
+// Failing to record is logged rather than returned so it never masks the
+// result of the rotation itself.
+func (b *backend) recordRotationOutcome(ctx context.Context, s logical.Storage, name string, outcome rotationOutcome) {
+	// Serialize with other rotations so concurrent outcomes are not lost.
+	lock := b.configLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	status, err := getRotationStatus(ctx, s, name)
+	if err != nil {
+		b.Logger().Warn("Failed to read rotation status", "connection", name, "error", err)
+		status = &rotationStatus{}
+	}
+
+	status.LastAttempt = outcome.Time
+	if outcome.Success {
+		// The error only describes a rotation that is still failing; the
+		// history keeps earlier failures.
+		status.LastSuccess = outcome.Time
+		status.LastError = ""
+		status.LastErrorTime = time.Time{}
+	} else {
+		status.LastError = outcome.Error
+		status.LastErrorTime = outcome.Time
+	}
+
+	status.History = append(status.History, outcome)
+	if len(status.History) > maxRotationHistory {
+		status.History = status.History[len(status.History)-maxRotationHistory:]
+	}
+
+	entry, err := logical.StorageEntryJSON(rotationStatusStoragePath(name), status)
+	if err == nil {
+		err = s.Put(ctx, entry)
+	}
+	if err != nil {
+		b.Logger().Warn("Failed to record rotation status", "connection", name, "error", err)
+	}
+}
+
+// formatOptionalTime formats t as RFC 3339, or returns an empty string for
+// the zero time.
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+const rotationStatusHelpSyn = `
+Read the admin API key rotation status and history of a connection.
+`
+
+const rotationStatusHelpDesc = `
+This endpoint returns the time of the last rotation attempt and the last
+successful rotation, the last rotation error, the next scheduled rotation when
+the rotation manager reports it, and the most recent rotation outcomes, newest
+first.
+
+Admin API key IDs are only returned by the "rotation-status/detailed" variant,
+so access to them can be limited to privileged callers by policy.
+`
@@ -0,0 +1,169 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readRotationStatus(t *testing.T, b *backend, storage logical.Storage, path string) *logical.Response {
+	t.Helper()
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      path,
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestRotationStatus_RecordsOutcomes(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	writeMockServerConfig(t, b, storage, mockServer, map[string]interface{}{
+		"rotation_period":         86400,
+		"rotation_retry_attempts": 1,
+	})
+
+	// Nothing has been attempted yet.
+	resp := readRotationStatus(t, b, storage, "config/rotation-status")
+	require.NotNil(t, resp)
+	assert.Equal(t, defaultConnectionName, resp.Data["connection"])
+	assert.Equal(t, "", resp.Data["last_attempt"])
+	assert.Empty(t, resp.Data["history"])
+	assert.Equal(t, testNextRotation.Format(time.RFC3339), resp.Data["next_scheduled_rotation"])
+
+	_, err := b.rotateConnectionCredential(ctx, storage, defaultConnectionName, rotationTriggerManual)
+	require.NoError(t, err)
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	rotatedKeyID := cfg.AdminAPIKeyID
+
+	mockServer.SetFailureMode("create_admin_key", 500, "admin keys unavailable")
+	_, err = b.rotateConnectionCredential(ctx, storage, defaultConnectionName, rotationTriggerScheduled)
+	require.Error(t, err)
+
+	resp = readRotationStatus(t, b, storage, "config/rotation-status")
+	require.NotNil(t, resp)
+	assert.NotEmpty(t, resp.Data["last_success"])
+	assert.NotEmpty(t, resp.Data["last_attempt"])
+	assert.Contains(t, resp.Data["last_error"], "admin keys unavailable")
+
+	history := resp.Data["history"].([]map[string]interface{})
+	require.Len(t, history, 2)
+	assert.Equal(t, rotationTriggerScheduled, history[0]["trigger"], "newest outcome first")
+	assert.Equal(t, false, history[0]["success"])
+	assert.Equal(t, rotationTriggerManual, history[1]["trigger"])
+	assert.Equal(t, true, history[1]["success"])
+	assert.NotContains(t, history[1], "new_admin_api_key_id", "key IDs require the detailed endpoint")
+
+	resp = readRotationStatus(t, b, storage, "config/rotation-status/detailed")
+	require.NotNil(t, resp)
+	history = resp.Data["history"].([]map[string]interface{})
+	require.Len(t, history, 2)
+	assert.Equal(t, TestAdminAPIKeyID, history[1]["old_admin_api_key_id"])
+	assert.Equal(t, rotatedKeyID, history[1]["new_admin_api_key_id"])
+
+	// A later success clears the error; the history keeps the failure.
+	mockServer.ClearFailureMode()
+	_, err = b.rotateConnectionCredential(ctx, storage, defaultConnectionName, rotationTriggerScheduled)
+	require.NoError(t, err)
+
+	resp = readRotationStatus(t, b, storage, "config/rotation-status")
+	require.NotNil(t, resp)
+	assert.Equal(t, "", resp.Data["last_error"])
+	assert.Equal(t, "", resp.Data["last_error_time"])
+	assert.Equal(t, resp.Data["last_attempt"], resp.Data["last_success"])
+	history = resp.Data["history"].([]map[string]interface{})
+	require.Len(t, history, 3)
+	assert.Equal(t, false, history[1]["success"])
+}
+
+func TestRotationStatus_ConcurrentRotationsChainKeyIDs(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	writeMockServerConfig(t, b, storage, mockServer, map[string]interface{}{"rotation_overlap": 3600})
+
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := b.rotateConnectionCredential(ctx, storage, defaultConnectionName, rotationTriggerManual)
+			errs <- err
+		}()
+	}
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+
+	// Each rotation records the key it actually replaced, so the outcomes
+	// form a chain from the original key to the current one.
+	status, err := getRotationStatus(ctx, storage, defaultConnectionName)
+	require.NoError(t, err)
+	require.Len(t, status.History, 2)
+	first, second := status.History[0], status.History[1]
+	assert.Equal(t, TestAdminAPIKeyID, first.OldAdminAPIKeyID)
+	assert.Equal(t, first.NewAdminAPIKeyID, second.OldAdminAPIKeyID)
+
+	cfg, err := getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.AdminAPIKeyID, second.NewAdminAPIKeyID)
+}
+
+func TestRotationStatus_HistoryIsBounded(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	for i := 0; i < maxRotationHistory+5; i++ {
+		b.recordRotationOutcome(ctx, storage, "prod", rotationOutcome{
+			Time:    time.Now(),
+			Trigger: rotationTriggerScheduled,
+			Error:   fmt.Sprintf("failure %d", i),
+		})
+	}
+
+	status, err := getRotationStatus(ctx, storage, "prod")
+	require.NoError(t, err)
+	require.Len(t, status.History, maxRotationHistory)
+	assert.Equal(t, "failure 5", status.History[0].Error)
+	assert.Equal(t, fmt.Sprintf("failure %d", maxRotationHistory+4), status.LastError)
+	assert.True(t, status.LastSuccess.IsZero())
+}
+
+func TestRotationStatus_NamedConnection(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	require.NoError(t, putConnectionConfig(ctx, storage, "prod", &openaiConfig{
+		AdminAPIKey:    "sk-admin-prod",
+		AdminAPIKeyID:  "key_prod",
+		OrganizationID: "org-prod",
+	}))
+	b.recordRotationOutcome(ctx, storage, "prod", rotationOutcome{Time: time.Now(), Trigger: rotationTriggerManual, Success: true})
+
+	resp := readRotationStatus(t, b, storage, "config/prod/rotation-status")
+	require.NotNil(t, resp)
+	assert.Equal(t, "prod", resp.Data["connection"])
+	assert.Len(t, resp.Data["history"], 1)
+	assert.NotContains(t, resp.Data, "next_scheduled_rotation", "no rotation job is registered")
+
+	// The default connection has no status of its own.
+	assert.Nil(t, readRotationStatus(t, b, storage, "config/rotation-status"))
+}
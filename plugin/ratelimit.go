@@ -0,0 +1,236 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultRateLimit is the default number of requests per second a client
+	// sends to the OpenAI API.
+	DefaultRateLimit = 10.0
+
+	// DefaultMaxInFlight is the default number of concurrent requests a
+	// client sends to the OpenAI API.
+	DefaultMaxInFlight = 10
+
+	// DefaultMaxQueuedRequests is the default number of requests that may wait
+	// for admission before further requests are rejected.
+	DefaultMaxQueuedRequests = 100
+)
+
+// errClientOverloaded is returned when a request cannot be admitted. It wraps
+// consts.ErrOverloaded so Vault answers with 503, which clients retry.
+var errClientOverloaded = fmt.Errorf("too many concurrent OpenAI API requests: %w", consts.ErrOverloaded)
+
+// admissionControl limits the requests a client sends to the OpenAI API with
+// a token bucket and a cap on requests in flight. Requests that cannot be
+// admitted immediately wait in a bounded queue. The bucket slows down, or
+// pauses, when OpenAI reports that its rate limit is nearly used up.
+type admissionControl struct {
+	limiter   *rate.Limiter
+	inFlight  chan struct{}
+	maxQueued int
+
+	mu          sync.Mutex
+	queued      int
+	configured  rate.Limit
+	pausedUntil time.Time
+
+	// now is overridden in tests.
+	now func() time.Time
+}
+
+// newAdmissionControl returns an admission control for the given limits,
+// using the defaults for zero values.
+func newAdmissionControl(requestsPerSecond float64, maxInFlight, maxQueued int) *admissionControl {
+	if requestsPerSecond == 0 {
+		requestsPerSecond = DefaultRateLimit
+	}
+	if maxInFlight == 0 {
+		maxInFlight = DefaultMaxInFlight
+	}
+	if maxQueued == 0 {
+		maxQueued = DefaultMaxQueuedRequests
+	}
+	burst := max(1, int(requestsPerSecond))
+	return &admissionControl{
+		limiter:    rate.NewLimiter(rate.Limit(requestsPerSecond), burst),
+		inFlight:   make(chan struct{}, maxInFlight),
+		maxQueued:  maxQueued,
+		configured: rate.Limit(requestsPerSecond),
+		now:        time.Now,
+	}
+}
+
+// admissionLimits are the settings an admission control is built from.
+type admissionLimits struct {
+	requestsPerSecond float64
+	maxInFlight       int
+	maxQueued         int
+}
+
+// admissionPool keeps one admission control for each connection of a mount,
+// so the rate limit and in-flight cap apply to all of a connection's clients
+// together, including the short-lived ones built for rotation, and survive
+// client rebuilds. A connection's admission control is replaced only when its
+// limits change; OpenAI's pause carries over to the new one.
+type admissionPool struct {
+	mu       sync.Mutex
+	controls map[string]*pooledAdmission
+}
+
+// pooledAdmission is a connection's admission control and the limits it was
+// built from.
+type pooledAdmission struct {
+	limits  admissionLimits
+	control *admissionControl
+}
+
+// newAdmissionPool returns an empty admission pool.
+func newAdmissionPool() *admissionPool {
+	return &admissionPool{controls: make(map[string]*pooledAdmission)}
+}
+
+// get returns the admission control of the connection named in config,
+// building it on first use or when the connection's limits have changed.
+// Clients that belong to no connection get an admission control of their own.
+func (p *admissionPool) get(config *Config) *admissionControl {
+	if config.Connection == "" {
+		return newAdmissionControl(config.RateLimit, config.MaxInFlight, config.MaxQueuedRequests)
+	}
+	limits := admissionLimits{config.RateLimit, config.MaxInFlight, config.MaxQueuedRequests}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current, ok := p.controls[config.Connection]
+	if ok && current.limits == limits {
+		return current.control
+	}
+	control := newAdmissionControl(limits.requestsPerSecond, limits.maxInFlight, limits.maxQueued)
+	if ok {
+		current.control.mu.Lock()
+		control.pausedUntil = current.control.pausedUntil
+		current.control.mu.Unlock()
+	}
+	p.controls[config.Connection] = &pooledAdmission{limits: limits, control: control}
+	return control
+}
+
+// remove drops the named connection's admission control, for when the
+// connection is deleted.
+func (p *admissionPool) remove(connection string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.controls, connection)
+}
+
+// validateAdmissionLimits checks the configured rate limit settings.
+func validateAdmissionLimits(requestsPerSecond float64, maxInFlight, maxQueued int) error {
+	if requestsPerSecond < 0 || maxInFlight < 0 || maxQueued < 0 {
+		return fmt.Errorf("rate_limit, max_in_flight and max_queued_requests must not be negative")
+	}
+	return nil
+}
+
+// acquire waits until a request may be sent and returns the function that
+// releases its slot. While OpenAI reports the rate limit as exhausted, it
+// waits out the pause with sleep before taking an in-flight slot, so paused
+// requests do not hold slots. It fails with errClientOverloaded when the
+// queue is full, the pause is longer than maxPause, or ctx ends before the
+// request is admitted.
+func (a *admissionControl) acquire(ctx context.Context, sleep func(context.Context, time.Duration) error, maxPause time.Duration) (func(), error) {
+	a.mu.Lock()
+	if a.queued >= a.maxQueued {
+		a.mu.Unlock()
+		return nil, errClientOverloaded
+	}
+	a.queued++
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.queued--
+		a.mu.Unlock()
+	}()
+
+	if pause := a.pause(); pause > 0 {
+		if pause > maxPause {
+			return nil, fmt.Errorf("%w (OpenAI rate limit exhausted for another %s)", errClientOverloaded, pause.Round(time.Second))
+		}
+		if err := sleep(ctx, pause); err != nil {
+			return nil, fmt.Errorf("%w (%v)", errClientOverloaded, err)
+		}
+	}
+
+	select {
+	case a.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w (%v)", errClientOverloaded, ctx.Err())
+	}
+	release := func() { <-a.inFlight }
+
+	if err := a.limiter.Wait(ctx); err != nil {
+		release()
+		return nil, fmt.Errorf("%w (%v)", errClientOverloaded, err)
+	}
+	return release, nil
+}
+
+// pause returns how long admission is paused because OpenAI reported the
+// rate limit as exhausted.
+func (a *admissionControl) pause() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.pausedUntil.Sub(a.now())
+}
+
+// observe adapts the limits to the rate limit state OpenAI reports in a
+// response. With no requests remaining, admission pauses until the limit
+// resets; otherwise the remaining requests are spread over the reset window
+// when that is slower than the configured rate.
+func (a *admissionControl) observe(statusCode int, header http.Header) {
+	if header == nil {
+		return
+	}
+	now := a.now()
+
+	if statusCode == http.StatusTooManyRequests {
+		if wait := retryAfterDelay(statusCode, header, now); wait > 0 {
+			a.pauseUntil(now.Add(wait))
+		}
+	}
+
+	remaining, err := strconv.Atoi(header.Get("X-Ratelimit-Remaining-Requests"))
+	if err != nil {
+		return
+	}
+	reset, err := time.ParseDuration(header.Get("X-Ratelimit-Reset-Requests"))
+	if err != nil || reset <= 0 {
+		return
+	}
+
+	if remaining <= 0 {
+		a.pauseUntil(now.Add(reset))
+		return
+	}
+	a.limiter.SetLimit(min(a.configured, rate.Limit(float64(remaining)/reset.Seconds())))
+}
+
+// pauseUntil pauses admission until t, unless it is already paused longer.
+func (a *admissionControl) pauseUntil(t time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if t.After(a.pausedUntil) {
+		a.pausedUntil = t
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestAdmissionControl_Defaults(t *testing.T) {
+	a := newAdmissionControl(0, 0, 0)
+	assert.Equal(t, rate.Limit(DefaultRateLimit), a.limiter.Limit())
+	assert.Equal(t, DefaultMaxInFlight, cap(a.inFlight))
+	assert.Equal(t, DefaultMaxQueuedRequests, a.maxQueued)
+
+	require.NoError(t, validateAdmissionLimits(0, 0, 0))
+	require.Error(t, validateAdmissionLimits(-1, 0, 0))
+	require.Error(t, validateAdmissionLimits(0, 0, -1))
+}
+
+func TestAdmissionControl_QueueFull(t *testing.T) {
+	a := newAdmissionControl(1000, 1, 1)
+	ctx := context.Background()
+
+	release, err := a.acquire(ctx, sleepContext, time.Minute)
+	require.NoError(t, err)
+
+	// The second request waits for the in-flight slot and fills the queue.
+	admitted := make(chan error, 1)
+	go func() {
+		release, err := a.acquire(ctx, sleepContext, time.Minute)
+		if err == nil {
+			release()
+		}
+		admitted <- err
+	}()
+	require.Eventually(t, func() bool {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return a.queued == 1
+	}, time.Second, time.Millisecond)
+
+	_, err = a.acquire(ctx, sleepContext, time.Minute)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, consts.ErrOverloaded))
+
+	release()
+	require.NoError(t, <-admitted)
+}
+
+func TestAdmissionControl_InFlightCapHonorsContext(t *testing.T) {
+	a := newAdmissionControl(1000, 1, 10)
+	release, err := a.acquire(context.Background(), sleepContext, time.Minute)
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = a.acquire(ctx, sleepContext, time.Minute)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, consts.ErrOverloaded))
+	assert.Contains(t, err.Error(), "deadline exceeded")
+}
+
+func TestAdmissionControl_Pause(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	a := newAdmissionControl(1000, 1, 10)
+	a.now = func() time.Time { return now }
+	a.pauseUntil(now.Add(2 * time.Second))
+
+	// The pause is waited out with the given sleep, without holding the
+	// in-flight slot.
+	var waits []time.Duration
+	sleep := func(_ context.Context, d time.Duration) error {
+		assert.Empty(t, a.inFlight, "a paused request must not hold an in-flight slot")
+		waits = append(waits, d)
+		now = now.Add(d)
+		return nil
+	}
+	release, err := a.acquire(context.Background(), sleep, time.Minute)
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, []time.Duration{2 * time.Second}, waits)
+
+	// A pause longer than the caller may wait fails fast.
+	a.pauseUntil(now.Add(time.Hour))
+	waits = nil
+	_, err = a.acquire(context.Background(), sleep, time.Minute)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, consts.ErrOverloaded))
+	assert.Contains(t, err.Error(), "rate limit exhausted for another 1h0m0s")
+	assert.Empty(t, waits)
+	assert.Empty(t, a.inFlight)
+}
+
+func TestAdmissionControl_Observe(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	a := newAdmissionControl(10, 0, 0)
+	a.now = func() time.Time { return now }
+
+	// Remaining requests are spread over the reset window.
+	a.observe(http.StatusOK, http.Header{
+		"X-Ratelimit-Remaining-Requests": {"5"},
+		"X-Ratelimit-Reset-Requests":     {"10s"},
+	})
+	assert.Equal(t, rate.Limit(0.5), a.limiter.Limit())
+	assert.LessOrEqual(t, a.pause(), time.Duration(0))
+
+	// The configured rate is never exceeded.
+	a.observe(http.StatusOK, http.Header{
+		"X-Ratelimit-Remaining-Requests": {"1000"},
+		"X-Ratelimit-Reset-Requests":     {"1s"},
+	})
+	assert.Equal(t, rate.Limit(10), a.limiter.Limit())
+
+	// An exhausted limit pauses admission until it resets.
+	a.observe(http.StatusOK, http.Header{
+		"X-Ratelimit-Remaining-Requests": {"0"},
+		"X-Ratelimit-Reset-Requests":     {"2s"},
+	})
+	assert.Equal(t, 2*time.Second, a.pause())
+
+	// A 429 pauses for as long as Retry-After asks, unless already paused longer.
+	a.observe(http.StatusTooManyRequests, http.Header{"Retry-After": {"5"}})
+	assert.Equal(t, 5*time.Second, a.pause())
+	a.observe(http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	assert.Equal(t, 5*time.Second, a.pause())
+}
+
+func TestClient_AdaptsToRateLimitHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Ratelimit-Remaining-Requests", "30")
+		w.Header().Set("X-Ratelimit-Reset-Requests", "1m")
+		_, _ = w.Write([]byte(`{"object": "list", "data": []}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:    "test-key",
+		OrganizationID: "org-123",
+		APIEndpoint:    server.URL,
+		RateLimit:      20,
+		MaxInFlight:    2,
+	}))
+	assert.Equal(t, 2, cap(client.admission.inFlight))
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, rate.Limit(0.5), client.admission.limiter.Limit())
+	assert.Empty(t, client.admission.inFlight, "the in-flight slot must be released")
+}
+
+func TestConfig_RateLimit(t *testing.T) {
+	b := getTestBackend(t)
+	offlineAdminKeyLookups(b)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	req := &logical.Request{Storage: storage, MountPoint: TestMountPoint, Path: TestConfigPath}
+
+	write := func(raw map[string]interface{}) *logical.Response {
+		raw["verify_connection"] = false
+		resp, err := b.pathConfigWrite(ctx, req, &framework.FieldData{
+			Raw:    raw,
+			Schema: b.pathAdminConfig()[1].Fields,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := write(map[string]interface{}{
+		"admin_api_key":    TestAPIKey,
+		"admin_api_key_id": TestAdminAPIKeyID,
+		"organization_id":  TestOrganizationID,
+		"max_in_flight":    -1,
+	})
+	require.NotNil(t, resp)
+	assert.True(t, resp.IsError())
+	assert.Contains(t, resp.Error().Error(), "must not be negative")
+
+	resp = write(map[string]interface{}{
+		"admin_api_key":    TestAPIKey,
+		"admin_api_key_id": TestAdminAPIKeyID,
+		"organization_id":  TestOrganizationID,
+		"rate_limit":       2.5,
+		"max_in_flight":    4,
+	})
+	require.Nil(t, resp)
+
+	resp, err := b.pathConfigRead(ctx, req, &framework.FieldData{})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, 2.5, resp.Data["rate_limit"])
+	assert.Equal(t, 4, resp.Data["max_in_flight"])
+	assert.Equal(t, DefaultMaxQueuedRequests, resp.Data["max_queued_requests"])
+
+	client := b.getClient(defaultConnectionName).(*Client)
+	assert.Equal(t, rate.Limit(2.5), client.admission.limiter.Limit())
+	assert.Equal(t, 4, cap(client.admission.inFlight))
+}
+
+func TestAdmissionPool_OnePerConnection(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	pool := newAdmissionPool()
+
+	first := pool.get(&Config{Connection: "default", AdminAPIKey: "sk-admin-old", MaxInFlight: 2})
+	assert.Same(t, first, pool.get(&Config{Connection: "default", AdminAPIKey: "sk-admin-new", MaxInFlight: 2}),
+		"credential changes keep the admission control")
+	assert.NotSame(t, first, pool.get(&Config{Connection: "other", MaxInFlight: 2}))
+	assert.NotSame(t, first, pool.get(&Config{MaxInFlight: 2}), "clients without a connection are not pooled")
+
+	// New limits replace the admission control, but not OpenAI's pause.
+	first.now = func() time.Time { return now }
+	first.pauseUntil(now.Add(time.Minute))
+	wider := pool.get(&Config{Connection: "default", MaxInFlight: 4})
+	assert.NotSame(t, first, wider)
+	assert.Equal(t, 4, cap(wider.inFlight))
+	assert.Equal(t, now.Add(time.Minute), wider.pausedUntil)
+	assert.Len(t, pool.controls, 2)
+
+	pool.remove("other")
+	assert.Len(t, pool.controls, 1)
+}
+
+func TestBackend_ClientsShareInFlightCap(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-unblock
+		_, _ = w.Write([]byte(`{"object": "list", "data": []}`))
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	b := getTestBackend(t)
+	newClient := func(key string) ClientAPI {
+		client, err := b.newClient(&Config{
+			Connection:           defaultConnectionName,
+			AdminAPIKey:          key,
+			OrganizationID:       TestOrganizationID,
+			APIEndpoint:          server.URL,
+			MaxInFlight:          1,
+			RequestRetryAttempts: 1,
+		}, hclog.NewNullLogger())
+		require.NoError(t, err)
+		return client
+	}
+	cached := newClient(TestAPIKey)
+	rotation := newClient("sk-admin-new")
+
+	go func() { _, _ = cached.ListAdminAPIKeys(context.Background()) }()
+	<-started
+
+	// The connection's only in-flight slot is taken by the other client.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := rotation.ListAdminAPIKeys(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, consts.ErrOverloaded), "unexpected error: %v", err)
+}
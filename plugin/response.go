@@ -0,0 +1,163 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Object types of the create service account response.
+const (
+	serviceAccountObject       = "organization.project.service_account"
+	serviceAccountAPIKeyObject = "organization.project.service_account.api_key"
+)
+
+// Fields of the create service account response the client knows about.
+// Other fields are reported as API drift.
+var (
+	knownServiceAccountFields = []string{"object", "id", "name", "role", "created_at", "api_key", "service_account"}
+	knownAPIKeyFields         = []string{"object", "id", "name", "value", "created_at", "expires_at", "service_account_id"}
+)
+
+// createServiceAccountResponse is the response of the create service account
+// endpoint. OpenAI returns the service account at the top level with its key
+// in api_key; the service_account envelope of ServiceAccountResponse is
+// accepted as well.
+type createServiceAccountResponse struct {
+	ServiceAccountResponse
+
+	Object    string    `json:"object"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt *UnixTime `json:"created_at"`
+}
+
+// serviceAccount returns the service account of the response, from
+// whichever shape it has.
+func (r *createServiceAccountResponse) serviceAccount(projectID string) *ServiceAccount {
+	if r.ID == "" && r.ServiceAccount != nil {
+		svc := *r.ServiceAccount
+		svc.ProjectID = projectID
+		return &svc
+	}
+	return &ServiceAccount{
+		ID:        r.ID,
+		ProjectID: projectID,
+		Name:      r.Name,
+		Role:      r.Role,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// decodeCreateServiceAccountResponse decodes and validates the response of
+// the create service account endpoint. A response without the account ID,
+// key ID or key value is rejected with ErrUnexpectedResponse; if it names
+// the account, the account is deleted so no key is left behind that Vault
+// does not track. Fields the client does not know are logged as API drift.
+func (c *Client) decodeCreateServiceAccountResponse(ctx context.Context, projectID string, body []byte) (*ServiceAccount, *APIKey, error) {
+	var resp createServiceAccountResponse
+	decodeErr := json.Unmarshal(body, &resp)
+	svc := resp.serviceAccount(projectID)
+
+	var missing []string
+	if svc.ID == "" {
+		missing = append(missing, "id")
+	}
+	if resp.APIKey == nil {
+		missing = append(missing, "api_key")
+	} else {
+		if resp.APIKey.ID == "" {
+			missing = append(missing, "api_key.id")
+		}
+		if resp.APIKey.Value == "" {
+			missing = append(missing, "api_key.value")
+		}
+	}
+
+	c.logResponseDrift(body, resp.Object, missing)
+
+	var err error
+	switch {
+	case decodeErr != nil:
+		err = fmt.Errorf("%w: error parsing create service account response: %v", ErrUnexpectedResponse, decodeErr)
+	case len(missing) > 0:
+		err = fmt.Errorf("%w: create service account response is missing %s", ErrUnexpectedResponse, strings.Join(missing, ", "))
+	}
+	if err != nil {
+		if svc.ID != "" {
+			err = c.deleteOrphanedServiceAccount(ctx, projectID, svc.ID, err)
+		}
+		return nil, nil, err
+	}
+
+	apiKey := *resp.APIKey
+	apiKey.ServiceAccID = svc.ID
+	return svc, &apiKey, nil
+}
+
+// deleteOrphanedServiceAccount deletes a service account whose creation
+// response could not be used, and returns cause with the outcome. The
+// deletion runs even if the Vault request has been cancelled.
+func (c *Client) deleteOrphanedServiceAccount(ctx context.Context, projectID, id string, cause error) error {
+	c.logger.Warn("Deleting service account created with an unusable response",
+		"service_account_id", id,
+		"project_id", projectID,
+		"error", cause)
+	if err := c.DeleteServiceAccount(context.WithoutCancel(ctx), id, projectID); err != nil {
+		return fmt.Errorf("%w; service account %s could not be deleted and must be removed manually: %v", cause, id, err)
+	}
+	return fmt.Errorf("%w; service account %s was deleted", cause, id)
+}
+
+// logResponseDrift warns about differences between the create service
+// account response and the shape the client expects: unknown fields,
+// unexpected object types and missing required fields. Only field names are
+// logged, never values, since the response contains an API key.
+func (c *Client) logResponseDrift(body []byte, object string, missing []string) {
+	var fields, keyFields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		c.logger.Warn("OpenAI API response drift: create service account response is not a JSON object")
+		return
+	}
+	if raw, ok := fields["api_key"]; ok {
+		_ = json.Unmarshal(raw, &keyFields)
+	}
+
+	unknown := unknownFields(fields, knownServiceAccountFields, "")
+	unknown = append(unknown, unknownFields(keyFields, knownAPIKeyFields, "api_key.")...)
+
+	var keyObject string
+	if raw, ok := keyFields["object"]; ok {
+		_ = json.Unmarshal(raw, &keyObject)
+	}
+	unexpectedObject := (object != "" && object != serviceAccountObject) ||
+		(keyObject != "" && keyObject != serviceAccountAPIKeyObject)
+
+	if len(unknown) == 0 && len(missing) == 0 && !unexpectedObject {
+		return
+	}
+	c.logger.Warn("OpenAI API response drift: create service account response differs from the expected shape",
+		"object", object,
+		"api_key_object", keyObject,
+		"unknown_fields", unknown,
+		"missing_fields", missing)
+}
+
+// unknownFields returns the sorted names of the fields not in known, each
+// with prefix.
+func unknownFields(fields map[string]json.RawMessage, known []string, prefix string) []string {
+	var unknown []string
+	for name := range fields {
+		if !slices.Contains(known, name) {
+			unknown = append(unknown, prefix+name)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
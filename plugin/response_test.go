@@ -0,0 +1,185 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createResponseServer returns a client whose create service account calls
+// receive body. It records the paths of delete requests, which fail with
+// deleteStatus when it is not 0.
+func createResponseServer(t *testing.T, body string, deleteStatus int) (*Client, *bytes.Buffer, func() []string) {
+	var mu sync.Mutex
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mu.Unlock()
+			if deleteStatus != 0 {
+				w.WriteHeader(deleteStatus)
+				return
+			}
+			_, _ = w.Write([]byte(`{"object": "organization.project.service_account.deleted", "deleted": true}`))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	var logs bytes.Buffer
+	client := NewClient(TestAPIKey, hclog.New(&hclog.LoggerOptions{Output: &logs, Level: hclog.Warn}))
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:          TestAPIKey,
+		APIEndpoint:          server.URL,
+		OrganizationID:       TestOrganizationID,
+		RequestRetryAttempts: 1,
+	}))
+	return client, &logs, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return deleted
+	}
+}
+
+func createTestServiceAccount(client *Client) (*ServiceAccount, *APIKey, error) {
+	return client.CreateServiceAccount(context.Background(), TestProjectID, CreateServiceAccountRequest{Name: "test-account"})
+}
+
+func TestCreateServiceAccount_FlatResponse(t *testing.T) {
+	client, logs, deleted := createResponseServer(t, `{
+		"object": "organization.project.service_account",
+		"id": "svc_1",
+		"name": "test-account",
+		"role": "member",
+		"created_at": 1711471533,
+		"api_key": {
+			"object": "organization.project.service_account.api_key",
+			"id": "key_1",
+			"name": "Secret Key",
+			"value": "sk-abcdefghijklmnop",
+			"created_at": 1711471533
+		}
+	}`, 0)
+
+	svc, key, err := createTestServiceAccount(client)
+	require.NoError(t, err)
+	assert.Equal(t, "svc_1", svc.ID)
+	assert.Equal(t, TestProjectID, svc.ProjectID)
+	assert.Equal(t, "member", svc.Role)
+	require.NotNil(t, svc.GetCreatedAt())
+	assert.Equal(t, int64(1711471533), svc.GetCreatedAt().Unix())
+	assert.Equal(t, "key_1", key.ID)
+	assert.Equal(t, "sk-abcdefghijklmnop", key.Value)
+	assert.Equal(t, "svc_1", key.ServiceAccID)
+
+	assert.Empty(t, deleted())
+	assert.NotContains(t, logs.String(), "drift")
+}
+
+func TestCreateServiceAccount_LogsDrift(t *testing.T) {
+	client, logs, _ := createResponseServer(t, `{
+		"object": "organization.project.service_account.v2",
+		"id": "svc_1",
+		"name": "test-account",
+		"labels": {"team": "ml"},
+		"api_key": {"id": "key_1", "value": "sk-abcdefghijklmnop", "scopes": ["all"]}
+	}`, 0)
+
+	_, _, err := createTestServiceAccount(client)
+	require.NoError(t, err, "unknown fields do not fail the request")
+
+	assert.Contains(t, logs.String(), "drift")
+	assert.Contains(t, logs.String(), "labels")
+	assert.Contains(t, logs.String(), "api_key.scopes")
+	assert.Contains(t, logs.String(), "organization.project.service_account.v2")
+	assert.NotContains(t, logs.String(), "sk-abcdefghijklmnop", "key values are never logged")
+}
+
+func TestCreateServiceAccount_InvalidResponses(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		deleteStatus int
+		missing      string
+		deletes      bool
+		want         string
+	}{
+		{
+			name:    "missing key",
+			body:    `{"object": "organization.project.service_account", "id": "svc_1", "name": "test-account"}`,
+			missing: "api_key",
+			deletes: true,
+			want:    "service account svc_1 was deleted",
+		},
+		{
+			name:    "missing key value",
+			body:    `{"id": "svc_1", "api_key": {"id": "key_1"}}`,
+			missing: "api_key.value",
+			deletes: true,
+			want:    "service account svc_1 was deleted",
+		},
+		{
+			name:         "cleanup fails",
+			body:         `{"id": "svc_1", "api_key": {"value": "sk-abcdefghijklmnop"}}`,
+			deleteStatus: http.StatusInternalServerError,
+			missing:      "api_key.id",
+			deletes:      true,
+			want:         "service account svc_1 could not be deleted and must be removed manually",
+		},
+		{
+			name:    "missing account ID",
+			body:    `{"name": "test-account", "api_key": {"id": "key_1", "value": "sk-abcdefghijklmnop"}}`,
+			missing: "id",
+		},
+		{
+			name: "not JSON",
+			body: `<html>ok</html>`,
+			want: "error parsing create service account response",
+		},
+		{
+			name:    "wrong type",
+			body:    `{"id": "svc_1", "api_key": "sk-abcdefghijklmnop"}`,
+			deletes: true,
+			want:    "error parsing create service account response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, logs, deleted := createResponseServer(t, tt.body, tt.deleteStatus)
+
+			svc, key, err := createTestServiceAccount(client)
+			require.Error(t, err)
+			assert.Nil(t, svc)
+			assert.Nil(t, key)
+			assert.True(t, errors.Is(err, ErrUnexpectedResponse))
+			assert.NotContains(t, err.Error(), "service account data missing")
+			if tt.missing != "" {
+				assert.Contains(t, err.Error(), "missing "+tt.missing)
+			}
+			if tt.want != "" {
+				assert.Contains(t, err.Error(), tt.want)
+			}
+			assert.Contains(t, logs.String(), "drift")
+
+			if tt.deletes {
+				assert.Equal(t, []string{"/organization/projects/" + TestProjectID + "/service_accounts/svc_1"}, deleted())
+			} else {
+				assert.Empty(t, deleted())
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/consts"
+)
+
+const (
+	// DefaultRotationRetryAttempts is the default number of attempts for each
+	// step of an admin key rotation.
+	DefaultRotationRetryAttempts = 3
+
+	// DefaultRotationRetryBaseDelay is the default delay before the first
+	// retry. It doubles with each further retry.
+	DefaultRotationRetryBaseDelay = time.Second
+
+	// DefaultRotationRetryMaxDelay caps the delay between retries.
+	DefaultRotationRetryMaxDelay = 30 * time.Second
+
+	// DefaultRequestRetryAttempts is the default number of attempts for a
+	// single OpenAI API request.
+	DefaultRequestRetryAttempts = 3
+
+	// DefaultRequestRetryBaseDelay is the default delay before the first
+	// request retry. It doubles with each further retry.
+	DefaultRequestRetryBaseDelay = time.Second
+
+	// DefaultRequestRetryMaxDelay caps the delay between request retries,
+	// including delays requested by OpenAI through Retry-After.
+	DefaultRequestRetryMaxDelay = 30 * time.Second
+)
+
+var (
+	defaultRotationRetryPolicy = retryPolicy{
+		Attempts:  DefaultRotationRetryAttempts,
+		BaseDelay: DefaultRotationRetryBaseDelay,
+		MaxDelay:  DefaultRotationRetryMaxDelay,
+	}
+
+	defaultRequestRetryPolicy = retryPolicy{
+		Attempts:  DefaultRequestRetryAttempts,
+		BaseDelay: DefaultRequestRetryBaseDelay,
+		MaxDelay:  DefaultRequestRetryMaxDelay,
+	}
+)
+
+// retryPolicy is a bounded exponential backoff.
+type retryPolicy struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// withDefaults returns p with its zero values replaced by those of def.
+func (p retryPolicy) withDefaults(def retryPolicy) retryPolicy {
+	if p.Attempts == 0 {
+		p.Attempts = def.Attempts
+	}
+	if p.BaseDelay == 0 {
+		p.BaseDelay = def.BaseDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+	return p
+}
+
+// validate checks the policy configured through the <name>_retry_* fields.
+func (p retryPolicy) validate(name string) error {
+	if p.Attempts < 0 || p.BaseDelay < 0 || p.MaxDelay < 0 {
+		return fmt.Errorf("%s retry settings must not be negative", name)
+	}
+	if p.MaxDelay < p.BaseDelay {
+		return fmt.Errorf("%s_retry_max_delay must not be less than %s_retry_base_delay", name, name)
+	}
+	return nil
+}
+
+// delay returns the wait after the given failed attempt (starting at 1).
+func (p retryPolicy) delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// retry calls op until it succeeds, the attempts are used up, or ctx is
+// done. onRetry, if set, is called before each wait. Waiting stops as soon as
+// ctx is cancelled, and the context error is returned wrapped with the last
+// operation error.
+func (p retryPolicy) retry(ctx context.Context, op func(attempt int) error, onRetry func(attempt int, err error, wait time.Duration)) error {
+	attempts := p.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = op(attempt); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		wait := p.delay(attempt)
+		if onRetry != nil {
+			onRetry(attempt, err, wait)
+		}
+		if ctxErr := sleepContext(ctx, wait); ctxErr != nil {
+			return fmt.Errorf("%w (last error: %v)", ctxErr, err)
+		}
+	}
+	return err
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// jitter spreads d over [d/2, d] so clients that failed together do not
+// retry together.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// retrySafe reports whether a request that got statusCode or err can be sent
+// again without risking a duplicate. GET and DELETE are idempotent. Other
+// methods, such as the POSTs that create service accounts and admin keys,
+// are only safe to repeat when OpenAI cannot have acted on the request: it
+// was rate limited, or the connection failed before it was sent.
+func retrySafe(method string, statusCode int, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodDelete:
+		return true
+	}
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return statusCode == 0 && requestNotSent(err)
+}
+
+// retryableRequest reports whether a failed request should be retried:
+// rate limits, server errors and connection errors are transient, provided
+// repeating the request is safe.
+func retryableRequest(ctx context.Context, method string, statusCode int, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	transient := (statusCode == 0 && !certificateError(err)) ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= http.StatusInternalServerError
+	return transient && retrySafe(method, statusCode, err)
+}
+
+// requestOutcomeUnknown reports whether a request that failed with err may
+// still have been acted on by OpenAI: the connection failed after the
+// request was sent, or OpenAI answered with a server error. Requests that
+// OpenAI rejected, or that never left the client, did not take effect.
+func requestOutcomeUnknown(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return !requestNotSent(err) && !errors.Is(err, consts.ErrOverloaded)
+}
+
+// requestNotSent reports whether err means the connection to OpenAI could
+// not be established, so no part of the request reached it.
+func requestNotSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// certificateError reports whether err is a TLS certificate verification
+// failure, which retrying cannot fix.
+func certificateError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	return errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr)
+}
+
+// retryAfterDelay returns how long OpenAI asked the client to wait before
+// retrying a rate-limited request, or 0 if it did not say. It honors
+// Retry-After, in seconds or as an HTTP date, retry-after-ms, and OpenAI's
+// x-ratelimit-reset-requests and x-ratelimit-reset-tokens headers, using the
+// longest wait given.
+func retryAfterDelay(statusCode int, header http.Header, now time.Time) time.Duration {
+	if header == nil {
+		return 0
+	}
+
+	var wait time.Duration
+	if v := header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			wait = max(wait, time.Duration(seconds)*time.Second)
+		} else if at, err := http.ParseTime(v); err == nil {
+			wait = max(wait, at.Sub(now))
+		}
+	}
+	if v := header.Get("Retry-After-Ms"); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil {
+			wait = max(wait, time.Duration(ms*float64(time.Millisecond)))
+		}
+	}
+
+	// The reset headers are sent with every response; they only say how long
+	// to wait when the request was rate limited.
+	if statusCode == http.StatusTooManyRequests {
+		for _, name := range []string{"X-Ratelimit-Reset-Requests", "X-Ratelimit-Reset-Tokens"} {
+			if d, err := time.ParseDuration(header.Get(name)); err == nil {
+				wait = max(wait, d)
+			}
+		}
+	}
+	return max(wait, 0)
+}
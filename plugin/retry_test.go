@@ -0,0 +1,288 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := retryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.delay(1))
+	assert.Equal(t, 2*time.Second, policy.delay(2))
+	assert.Equal(t, 4*time.Second, policy.delay(3))
+	assert.Equal(t, 5*time.Second, policy.delay(4))
+	assert.Equal(t, 5*time.Second, policy.delay(50))
+}
+
+func TestRetryPolicy_Retry(t *testing.T) {
+	policy := retryPolicy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		calls := 0
+		err := policy.retry(context.Background(), func(int) error {
+			calls++
+			if calls < 3 {
+				return errors.New("transient")
+			}
+			return nil
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("returns the last error when attempts are used up", func(t *testing.T) {
+		var retries []int
+		err := policy.retry(context.Background(), func(attempt int) error {
+			return errors.New("permanent")
+		}, func(attempt int, _ error, _ time.Duration) {
+			retries = append(retries, attempt)
+		})
+		require.EqualError(t, err, "permanent")
+		assert.Equal(t, []int{1, 2}, retries)
+	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		slow := retryPolicy{Attempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		calls := 0
+		err := slow.retry(ctx, func(int) error {
+			calls++
+			return errors.New("transient")
+		}, nil)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "transient")
+		assert.Equal(t, 1, calls)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestRetryPolicy_WithDefaultsAndValidate(t *testing.T) {
+	policy := retryPolicy{Attempts: 5}.withDefaults(defaultRequestRetryPolicy)
+	assert.Equal(t, 5, policy.Attempts)
+	assert.Equal(t, DefaultRequestRetryBaseDelay, policy.BaseDelay)
+	assert.Equal(t, DefaultRequestRetryMaxDelay, policy.MaxDelay)
+	require.NoError(t, policy.validate("request"))
+
+	err := retryPolicy{Attempts: -1}.validate("request")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request retry settings must not be negative")
+
+	err = retryPolicy{BaseDelay: time.Minute, MaxDelay: time.Second}.validate("request")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request_retry_max_delay")
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := jitter(time.Second)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, time.Second)
+	}
+	assert.Equal(t, time.Duration(0), jitter(0))
+}
+
+func TestRetryableRequest(t *testing.T) {
+	ctx := context.Background()
+	apiErr := errors.New("API error")
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name       string
+		method     string
+		statusCode int
+		err        error
+		want       bool
+	}{
+		{"GET rate limited", http.MethodGet, http.StatusTooManyRequests, apiErr, true},
+		{"GET server error", http.MethodGet, http.StatusBadGateway, apiErr, true},
+		{"GET connection reset", http.MethodGet, 0, readErr, true},
+		{"DELETE server error", http.MethodDelete, http.StatusServiceUnavailable, apiErr, true},
+		{"GET not found", http.MethodGet, http.StatusNotFound, apiErr, false},
+		{"POST rate limited", http.MethodPost, http.StatusTooManyRequests, apiErr, true},
+		{"POST connection refused", http.MethodPost, 0, dialErr, true},
+		{"POST connection reset", http.MethodPost, 0, readErr, false},
+		{"POST server error", http.MethodPost, http.StatusInternalServerError, apiErr, false},
+		{"success", http.MethodGet, http.StatusOK, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retryableRequest(ctx, tt.method, tt.statusCode, tt.err))
+		})
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, retryableRequest(cancelled, http.MethodGet, http.StatusBadGateway, apiErr))
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	header := http.Header{}
+	assert.Equal(t, time.Duration(0), retryAfterDelay(http.StatusTooManyRequests, header, now))
+
+	header.Set("Retry-After", "3")
+	assert.Equal(t, 3*time.Second, retryAfterDelay(http.StatusTooManyRequests, header, now))
+
+	header.Set("Retry-After", now.Add(10*time.Second).Format(http.TimeFormat))
+	assert.Equal(t, 10*time.Second, retryAfterDelay(http.StatusServiceUnavailable, header, now))
+
+	header = http.Header{}
+	header.Set("Retry-After-Ms", "1500")
+	assert.Equal(t, 1500*time.Millisecond, retryAfterDelay(http.StatusTooManyRequests, header, now))
+
+	// Rate-limit reset headers only apply to rate-limited responses.
+	header = http.Header{}
+	header.Set("X-Ratelimit-Reset-Requests", "6m0s")
+	header.Set("X-Ratelimit-Reset-Tokens", "20ms")
+	assert.Equal(t, 6*time.Minute, retryAfterDelay(http.StatusTooManyRequests, header, now))
+	assert.Equal(t, time.Duration(0), retryAfterDelay(http.StatusBadGateway, header, now))
+}
+
+// retryTestClient returns a client for server that records its retry waits
+// instead of sleeping.
+func retryTestClient(t *testing.T, server *httptest.Server, waits *[]time.Duration) *Client {
+	t.Helper()
+	client := NewClient(TestAPIKey, hclog.NewNullLogger())
+	require.NoError(t, client.SetConfig(&Config{
+		AdminAPIKey:    TestAPIKey,
+		OrganizationID: TestOrganizationID,
+		APIEndpoint:    server.URL,
+	}))
+	// Waits advance the clock of the admission control instead of sleeping.
+	now := time.Now()
+	client.admission.now = func() time.Time { return now }
+	client.sleep = func(_ context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		now = now.Add(d)
+		return nil
+	}
+	return client
+}
+
+func TestClient_RetriesWithRetryAfter(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object": "list", "data": []}`))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := retryTestClient(t, server, &waits)
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	assert.Equal(t, []time.Duration{7 * time.Second}, waits)
+}
+
+func TestClient_RetryGivesUp(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := retryTestClient(t, server, &waits)
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+	assert.Equal(t, int32(DefaultRequestRetryAttempts), atomic.LoadInt32(&hits))
+	require.Len(t, waits, DefaultRequestRetryAttempts-1)
+	assert.LessOrEqual(t, waits[0], DefaultRequestRetryBaseDelay)
+	assert.LessOrEqual(t, waits[1], 2*DefaultRequestRetryBaseDelay)
+}
+
+func TestClient_RetryAfterBeyondMaxDelay(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := retryTestClient(t, server, &waits)
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "a wait beyond the max delay must not be retried")
+	assert.Empty(t, waits)
+}
+
+func TestClient_PostNotRetriedOnServerError(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := retryTestClient(t, server, &waits)
+
+	// The key may have been created before the error, so a retry could
+	// create a second one.
+	_, _, err := client.CreateAdminAPIKey(context.Background(), "new-key")
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	assert.Empty(t, waits)
+}
+
+func TestClient_PostRetriedWhenRateLimited(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "key_new", "value": "sk-admin-new"}`))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := retryTestClient(t, server, &waits)
+
+	_, id, err := client.CreateAdminAPIKey(context.Background(), "new-key")
+	require.NoError(t, err)
+	assert.Equal(t, "key_new", id)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	assert.Len(t, waits, 1)
+}
+
+func TestRequestOutcomeUnknown(t *testing.T) {
+	assert.True(t, requestOutcomeUnknown(errors.New("connection reset by peer")))
+	assert.True(t, requestOutcomeUnknown(context.DeadlineExceeded))
+	assert.True(t, requestOutcomeUnknown(fmt.Errorf("error creating admin API key: %w", &APIError{StatusCode: http.StatusBadGateway})))
+	assert.False(t, requestOutcomeUnknown(&APIError{StatusCode: http.StatusTooManyRequests}))
+	assert.False(t, requestOutcomeUnknown(&APIError{StatusCode: http.StatusBadRequest}))
+	assert.False(t, requestOutcomeUnknown(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.False(t, requestOutcomeUnknown(ErrOpenAIUnavailable))
+}
@@ -5,141 +5,507 @@ package openaisecrets
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/mitchellh/mapstructure"
 )
 
 //------------------------------------------------------------------------------
 // Core Rotation Implementation
 //------------------------------------------------------------------------------
 
-// rotateAdminAPIKey rotates the admin API key
-func (b *backend) rotateAdminAPIKey(ctx context.Context, storage logical.Storage) (bool, error) {
+// adminKeyRotation identifies the keys of an admin key rotation, as read and
+// written under the connection's config lock. NewKeyID is empty unless the
+// rotation completed.
+type adminKeyRotation struct {
+	OldKeyID string
+	NewKeyID string
+}
+
+// rotateAdminAPIKey rotates the admin API key of the named connection. It
+// returns nil without an error when the connection has no admin key to
+// rotate. Once the connection's config has been read, the rotation is
+// returned even on failure, naming the key that was to be replaced.
+func (b *backend) rotateAdminAPIKey(ctx context.Context, storage logical.Storage, name string) (rotation *adminKeyRotation, err error) {
+	ctx, span := b.startSpan(ctx, "openai.admin_key.rotate", attrConnection.String(name))
+	defer func() {
+		span.SetAttributes(attrRotated.Bool(err == nil && rotation != nil))
+		endSpan(span, nil, err)
+	}()
+
+	lock := b.configLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// Get the existing configuration
-	config, err := getConfig(ctx, storage)
+	config, err := getConnectionConfig(ctx, storage, name)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	if config == nil || config.AdminAPIKey == "" {
-		return false, nil
+		return nil, nil
 	}
 
-	b.Logger().Info("Starting admin API key rotation")
+	b.Logger().Info("Starting admin API key rotation", "connection", name)
 
 	// Save the old admin key ID before rotation
 	oldAdminKeyID := config.AdminAPIKeyID
+	rotation = &adminKeyRotation{OldKeyID: oldAdminKeyID}
+
+	// The rotation retries each step with the connection's rotation retry
+	// policy, so the clients it uses send every request once. Retrying
+	// requests as well would multiply the attempts.
+	oldClientConfig := config.clientConfig(name)
+	oldClientConfig.RequestRetryAttempts = 1
 
 	// Create a new client with the existing admin API key
-	oldClient := NewClient(config.AdminAPIKey, b.Logger())
-	oldClientConfig := &Config{
-		AdminAPIKey:    config.AdminAPIKey,
-		APIEndpoint:    config.APIEndpoint,
-		OrganizationID: config.OrganizationID,
+	oldClient, err := b.newClient(oldClientConfig, b.Logger())
+	if err != nil {
+		return rotation, fmt.Errorf("error configuring client with old key: %w", err)
 	}
 
-	if err := oldClient.SetConfig(oldClientConfig); err != nil {
-		return false, fmt.Errorf("error configuring client with old key: %w", err)
+	// Use a random suffix for the key name so rotation timing is not
+	// disclosed to anyone who can list admin API keys in the OpenAI console.
+	// The name also identifies the key to the WAL rollback if the rotation
+	// fails before its ID is known.
+	keyNameSuffix, err := generateRandomString(8)
+	if err != nil {
+		return rotation, fmt.Errorf("error generating admin key name: %w", err)
 	}
+	newAdminKeyName := vaultAdminKeyNamePrefix + keyNameSuffix
 
-	// Create a new admin API key with retry logic
-	var newAdminKey, newAdminKeyID string
-	var createErr error
+	// Record the rotation before creating the key, so a key created by a
+	// rotation that never completes is revoked by the WAL rollback.
+	walID, err := framework.PutWAL(ctx, storage, adminKeyRotationWALKind, &adminKeyRotationWAL{
+		Connection: name,
+		KeyName:    newAdminKeyName,
+	})
+	if err != nil {
+		return rotation, fmt.Errorf("error writing WAL entry: %w", err)
+	}
 
-	// Try up to 3 times with exponential backoff
-	for attempt := 1; attempt <= 3; attempt++ {
-		// Use a random suffix for the key name so rotation timing is not
-		// disclosed to anyone who can list admin API keys in the OpenAI console.
-		keyNameSuffix, randErr := generateRandomString(8)
-		if randErr != nil {
-			keyNameSuffix = fmt.Sprintf("r%d", attempt) // safe fallback
-		}
+	// Create a new admin API key, retrying transient failures according to
+	// the connection's retry policy.
+	policy := config.rotationRetryPolicy()
+	var newAdminKey, newAdminKeyID string
+	var createOutcomeUnknown bool
+	err = policy.retry(ctx, func(attempt int) error {
 		b.Logger().Debug("Creating new admin API key", "attempt", attempt)
-		newAdminKey, newAdminKeyID, createErr = oldClient.CreateAdminAPIKey(ctx, fmt.Sprintf("vault-admin-key-%s", keyNameSuffix))
-
-		if createErr == nil && newAdminKey != "" && newAdminKeyID != "" {
-			break
+		key, keyID, err := oldClient.CreateAdminAPIKey(ctx, newAdminKeyName)
+		if err != nil {
+			createOutcomeUnknown = createOutcomeUnknown || requestOutcomeUnknown(err)
+			return err
 		}
-
-		if attempt < 3 {
-			backoffDuration := time.Duration(attempt*attempt) * time.Second
-			b.Logger().Warn("Failed to create admin key, retrying",
-				"attempt", attempt,
-				"error", createErr,
-				"retry_in", backoffDuration)
-			time.Sleep(backoffDuration)
+		if key == "" || keyID == "" {
+			createOutcomeUnknown = true
+			return fmt.Errorf("received empty admin key during rotation")
 		}
+		newAdminKey, newAdminKeyID = key, keyID
+		return nil
+	}, func(attempt int, err error, wait time.Duration) {
+		b.Logger().Warn("Failed to create admin key, retrying",
+			"attempt", attempt,
+			"error", err,
+			"retry_in", wait)
+	})
+	if err != nil {
+		return rotation, fmt.Errorf("error creating new admin key after retries: %w", err)
 	}
 
-	// If all retries failed
-	if createErr != nil {
-		return false, fmt.Errorf("error creating new admin key after retries: %w", createErr)
-	}
-
-	if newAdminKey == "" {
-		return false, fmt.Errorf("received empty admin key during rotation")
+	// Record the new key's ID alongside its name for the rollback.
+	if newWALID, err := framework.PutWAL(ctx, storage, adminKeyRotationWALKind, &adminKeyRotationWAL{
+		Connection: name,
+		KeyName:    newAdminKeyName,
+		KeyID:      newAdminKeyID,
+	}); err != nil {
+		b.Logger().Warn("Failed to record new admin key ID in WAL; rollback will match the key by name", "error", err)
+	} else {
+		if err := framework.DeleteWAL(ctx, storage, walID); err != nil {
+			b.Logger().Warn("Failed to delete superseded WAL entry", "error", err)
+		}
+		walID = newWALID
 	}
 
 	// Test the new key
-	newClient := NewClient(newAdminKey, b.Logger())
-	newClientConfig := &Config{
-		AdminAPIKey:    newAdminKey,
-		APIEndpoint:    config.APIEndpoint,
-		OrganizationID: config.OrganizationID,
-	}
+	newClientConfig := config.clientConfig(name)
+	newClientConfig.AdminAPIKey = newAdminKey
+	newClientConfig.AdminAPIKeyID = newAdminKeyID
+	testClientConfig := *newClientConfig
+	testClientConfig.RequestRetryAttempts = 1
 
-	if err := newClient.SetConfig(newClientConfig); err != nil {
-		return false, fmt.Errorf("error configuring client with new key: %w", err)
+	testClient, err := b.newClient(&testClientConfig, b.Logger())
+	if err != nil {
+		return rotation, fmt.Errorf("error configuring client with new key: %w", err)
 	}
 
-	// Test with the new key
+	// A newly created key can take a moment to become usable, so validation
+	// uses the same retry policy.
 	b.Logger().Debug("Testing new admin API key")
-	if err := newClient.TestConnection(ctx); err != nil {
-		return false, fmt.Errorf("new admin key failed validation: %w", err)
+	err = policy.retry(ctx, func(int) error {
+		return testClient.TestConnection(ctx)
+	}, func(attempt int, err error, wait time.Duration) {
+		b.Logger().Warn("New admin key failed validation, retrying",
+			"attempt", attempt,
+			"error", err,
+			"retry_in", wait)
+	})
+	if err != nil {
+		return rotation, fmt.Errorf("new admin key failed validation: %w", err)
 	}
 
-	// Update the configuration with the new key and new key ID
+	// The client that replaces the cached one retries requests as
+	// configured.
+	newClient, err := b.newClient(newClientConfig, b.Logger())
+	if err != nil {
+		return rotation, fmt.Errorf("error configuring client with new key: %w", err)
+	}
+
+	// Update the configuration with the new key and new key ID. The previous
+	// key is recorded as retired in the same write, so a live admin key is
+	// never left without a record in storage.
 	b.Logger().Info("New admin API key validated, updating configuration")
+	now := time.Now()
 	config.AdminAPIKey = newAdminKey
 	config.AdminAPIKeyID = newAdminKeyID
-	config.LastRotatedTime = time.Now()
+	config.LastRotatedTime = now
+	if oldAdminKeyID != "" {
+		config.RetiredAdminKeys = append(config.RetiredAdminKeys, retiredAdminKey{
+			ID:          oldAdminKeyID,
+			RetiredAt:   now,
+			RevokeAfter: now.Add(config.RotationOverlap),
+		})
+	} else {
+		b.Logger().Warn("No previous admin key ID found, skipping revocation")
+	}
 
 	// Save the updated configuration
-	entry, err := logical.StorageEntryJSON(configPath, config)
-	if err != nil {
-		return false, err
+	if err := putConnectionConfig(ctx, storage, name, config); err != nil {
+		return rotation, err
 	}
 
-	if err := storage.Put(ctx, entry); err != nil {
-		return false, err
+	// A create that failed after reaching OpenAI, such as one that timed
+	// out waiting for the response, may have left a key with the same name
+	// behind. Such keys are revoked before the WAL entry is deleted; if that
+	// fails, the entry is kept so the rollback revokes them.
+	if createOutcomeUnknown {
+		if err := b.revokeDuplicateAdminKeys(ctx, newClient, name, newAdminKeyName, newAdminKeyID); err != nil {
+			b.Logger().Warn("Failed to revoke admin keys left by retried creates; the WAL rollback will revoke them",
+				"connection", name, "error", err)
+			walID = ""
+		}
+	}
+
+	// The new key is now tracked in config. If deleting the WAL entry fails,
+	// the rollback sees the key in use and leaves it alone.
+	if walID != "" {
+		if err := framework.DeleteWAL(ctx, storage, walID); err != nil {
+			b.Logger().Warn("Failed to delete WAL entry after rotation", "error", err)
+		}
 	}
 
 	// Update the current client under the write lock so concurrent requests
 	// always see a consistent client reference.
-	b.Lock()
-	b.client = newClient
-	b.Unlock()
-
-	// Revoke the previous key now that the new one is confirmed and persisted.
-	// If revocation fails, the new key remains active and stored, while the
-	// previous key may still be active in OpenAI. Do not log the key ID here:
-	// admin key IDs are credential metadata and may be captured by logs or audit
-	// sinks. Deferred retry/cleanup belongs in the bounded-overlap follow-up
-	// design, where records have a proper lifecycle.
-	if oldAdminKeyID != "" {
+	b.setClient(name, newClient)
+
+	// Without an overlap window the previous key is revoked right away. A
+	// failure here does not fail the rotation: the key stays recorded as
+	// retired and the periodic function retries it.
+	if oldAdminKeyID != "" && config.RotationOverlap == 0 {
 		b.Logger().Debug("Cleaning up previous admin API key")
-		if err := newClient.RevokeAdminAPIKey(ctx, oldAdminKeyID); err != nil {
-			b.Logger().Error("Failed to revoke previous admin key; the key may still be active in OpenAI",
-				"error", err)
-			return false, err
+		if err := b.revokeRetiredAdminKeysLocked(ctx, storage, name, config, newClient); err != nil {
+			b.Logger().Error("Failed to save retired admin key state after revocation", "connection", name, "error", err)
 		}
-	} else {
-		b.Logger().Warn("No previous admin key ID found, skipping revocation")
 	}
 
 	b.Logger().Info("Admin API key rotation completed successfully")
 
-	return true, nil
+	rotation.NewKeyID = newAdminKeyID
+	return rotation, nil
+}
+
+// revokeDuplicateAdminKeys revokes the admin keys named keyName other than
+// keepID. They are created when a create request fails after OpenAI has
+// accepted it and is then retried.
+func (b *backend) revokeDuplicateAdminKeys(ctx context.Context, client ClientAPI, connection, keyName, keepID string) error {
+	keys, err := client.ListAdminAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key == nil || key.ID == "" || key.ID == keepID || key.Name != keyName {
+			continue
+		}
+		if err := client.RevokeAdminAPIKey(ctx, key.ID); err != nil {
+			return err
+		}
+		b.Logger().Info("Revoked admin API key left by a retried create", "connection", connection)
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+// Deferred Revocation of Retired Admin Keys
+//------------------------------------------------------------------------------
+
+const (
+	// retiredKeyRetryBaseDelay is the delay before the first retry of a
+	// failed revocation. It doubles with each failed attempt.
+	retiredKeyRetryBaseDelay = time.Minute
+
+	// retiredKeyRetryMaxDelay caps the delay between revocation retries.
+	retiredKeyRetryMaxDelay = time.Hour
+)
+
+// retiredAdminKey records an admin API key that has been replaced by
+// rotation but not yet revoked in OpenAI. Key values are never stored; only
+// the ID is needed to revoke the key.
+type retiredAdminKey struct {
+	ID          string    `json:"id"`
+	RetiredAt   time.Time `json:"retired_at"`
+	RevokeAfter time.Time `json:"revoke_after"`
+	Attempts    int       `json:"attempts,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// retiredKeyRetryDelay returns the delay before the next revocation attempt
+// after the given number of failed attempts.
+func retiredKeyRetryDelay(attempts int) time.Duration {
+	return retryPolicy{BaseDelay: retiredKeyRetryBaseDelay, MaxDelay: retiredKeyRetryMaxDelay}.delay(attempts)
+}
+
+// revokeRetiredAdminKeys revokes the named connection's retired admin keys
+// whose overlap window has ended. It is called from the periodic function.
+func (b *backend) revokeRetiredAdminKeys(ctx context.Context, storage logical.Storage, name string) error {
+	lock := b.configLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	config, err := getConnectionConfig(ctx, storage, name)
+	if err != nil {
+		return err
+	}
+	if config == nil || len(config.RetiredAdminKeys) == 0 {
+		return nil
+	}
+
+	client, err := b.newClient(config.clientConfig(name), b.Logger())
+	if err != nil {
+		return fmt.Errorf("error configuring OpenAI client: %w", err)
+	}
+
+	return b.revokeRetiredAdminKeysLocked(ctx, storage, name, config, client)
+}
+
+// revokeRetiredAdminKey revokes the named connection's retired admin key
+// keyID now, without waiting for its revocation time. A failed revocation is
+// recorded for retry like any other and returned.
+func (b *backend) revokeRetiredAdminKey(ctx context.Context, storage logical.Storage, name, keyID string) error {
+	lock := b.configLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	config, err := getConnectionConfig(ctx, storage, name)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+	index := slices.IndexFunc(config.RetiredAdminKeys, func(key retiredAdminKey) bool { return key.ID == keyID })
+	if index < 0 {
+		return nil
+	}
+	config.RetiredAdminKeys[index].RevokeAfter = time.Now()
+
+	client, err := b.newClient(config.clientConfig(name), b.Logger())
+	if err != nil {
+		return fmt.Errorf("error configuring OpenAI client: %w", err)
+	}
+	if err := b.revokeRetiredAdminKeysLocked(ctx, storage, name, config, client); err != nil {
+		return err
+	}
+	for _, key := range config.RetiredAdminKeys {
+		if key.ID == keyID {
+			return errors.New(key.LastError)
+		}
+	}
+	return nil
+}
+
+// revokeRetiredAdminKeysLocked revokes the due retired keys in config using
+// client, records failures for retry, and saves the config if anything
+// changed. The caller must hold the connection's config lock.
+func (b *backend) revokeRetiredAdminKeysLocked(ctx context.Context, storage logical.Storage, name string, config *openaiConfig, client ClientAPI) error {
+	now := time.Now()
+	pending := make([]retiredAdminKey, 0, len(config.RetiredAdminKeys))
+	changed := false
+
+	for _, key := range config.RetiredAdminKeys {
+		if now.Before(key.RevokeAfter) {
+			pending = append(pending, key)
+			continue
+		}
+
+		changed = true
+		// Do not log the key ID: admin key IDs are credential metadata and
+		// may be captured by logs or audit sinks.
+		err := client.RevokeAdminAPIKey(ctx, key.ID)
+		if errors.Is(err, ErrNotFound) {
+			b.Logger().Info("Retired admin API key was already revoked", "connection", name)
+			continue
+		}
+		if err != nil {
+			key.Attempts++
+			key.LastError = err.Error()
+			key.RevokeAfter = now.Add(retiredKeyRetryDelay(key.Attempts))
+			b.Logger().Error("Failed to revoke retired admin key; the key may still be active in OpenAI",
+				"connection", name, "attempts", key.Attempts, "retry_at", key.RevokeAfter, "error", err)
+			pending = append(pending, key)
+			continue
+		}
+		b.Logger().Info("Revoked retired admin API key", "connection", name)
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if len(pending) == 0 {
+		pending = nil
+	}
+	config.RetiredAdminKeys = pending
+	return putConnectionConfig(ctx, storage, name, config)
+}
+
+//------------------------------------------------------------------------------
+// WAL Rollback of Incomplete Rotations
+//------------------------------------------------------------------------------
+
+// adminKeyRotationWALKind is the WAL entry kind written by rotateAdminAPIKey.
+const adminKeyRotationWALKind = "adminKeyRotation"
+
+// adminKeyRotationWAL records an admin API key that a rotation is creating.
+// The entry is written before the key is created and deleted once the key is
+// saved in the connection's config. KeyID is only known after creation, so
+// the rollback also matches the key by its randomly generated name.
+type adminKeyRotationWAL struct {
+	Connection string `json:"connection" mapstructure:"connection"`
+	KeyName    string `json:"key_name" mapstructure:"key_name"`
+	KeyID      string `json:"key_id,omitempty" mapstructure:"key_id"`
+}
+
+// walRollback is called by Vault for WAL entries older than the rollback
+// minimum age.
+func (b *backend) walRollback(ctx context.Context, req *logical.Request, kind string, data interface{}) error {
+	switch kind {
+	case adminKeyRotationWALKind:
+		return b.rollbackAdminKeyRotation(ctx, req.Storage, data)
+	default:
+		return fmt.Errorf("unknown WAL entry kind %q", kind)
+	}
+}
+
+// rollbackAdminKeyRotation revokes the admin key created by a rotation that
+// never completed. Keys that are in use by the connection, or awaiting
+// deferred revocation, are never revoked here.
+func (b *backend) rollbackAdminKeyRotation(ctx context.Context, storage logical.Storage, data interface{}) error {
+	var entry adminKeyRotationWAL
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return fmt.Errorf("error decoding WAL entry: %w", err)
+	}
+
+	// Hold the config lock so an in-progress rotation is never rolled back.
+	lock := b.configLock(entry.Connection)
+	lock.Lock()
+	defer lock.Unlock()
+
+	config, err := getConnectionConfig(ctx, storage, entry.Connection)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		// Keep the entry: the key can only be revoked with the connection's
+		// admin key, and the connection may be configured again.
+		return fmt.Errorf("connection %q of an incomplete admin key rotation is no longer configured; revoke the admin key named %q in OpenAI manually or configure the connection again",
+			entry.Connection, entry.KeyName)
+	}
+	return b.rollbackAdminKeyRotationLocked(ctx, entry, config)
+}
+
+// rollbackConnectionRotations rolls back the incomplete rotations of the named
+// connection and deletes their WAL entries, for when the connection is about
+// to be deleted and the rollback would no longer have its admin key. The
+// caller must hold the connection's config lock.
+func (b *backend) rollbackConnectionRotations(ctx context.Context, storage logical.Storage, name string, config *openaiConfig) error {
+	walIDs, err := framework.ListWAL(ctx, storage)
+	if err != nil {
+		return fmt.Errorf("error listing WAL entries: %w", err)
+	}
+	for _, id := range walIDs {
+		wal, err := framework.GetWAL(ctx, storage, id)
+		if err != nil {
+			return fmt.Errorf("error reading WAL entry: %w", err)
+		}
+		if wal == nil || wal.Kind != adminKeyRotationWALKind {
+			continue
+		}
+		var entry adminKeyRotationWAL
+		if err := mapstructure.Decode(wal.Data, &entry); err != nil {
+			return fmt.Errorf("error decoding WAL entry: %w", err)
+		}
+		if entry.Connection != name {
+			continue
+		}
+		if err := b.rollbackAdminKeyRotationLocked(ctx, entry, config); err != nil {
+			return err
+		}
+		if err := framework.DeleteWAL(ctx, storage, id); err != nil {
+			return fmt.Errorf("error deleting WAL entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// rollbackAdminKeyRotationLocked revokes the admin key recorded in entry
+// using the connection's config. The caller must hold the connection's
+// config lock.
+func (b *backend) rollbackAdminKeyRotationLocked(ctx context.Context, entry adminKeyRotationWAL, config *openaiConfig) error {
+	inUse := map[string]bool{config.AdminAPIKeyID: true}
+	for _, key := range config.RetiredAdminKeys {
+		inUse[key.ID] = true
+	}
+
+	client, err := b.newClient(config.clientConfig(entry.Connection), b.Logger())
+	if err != nil {
+		return fmt.Errorf("error configuring OpenAI client: %w", err)
+	}
+
+	keys, err := client.ListAdminAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key == nil || key.ID == "" || inUse[key.ID] {
+			continue
+		}
+		matchesID := entry.KeyID != "" && key.ID == entry.KeyID
+		matchesName := entry.KeyName != "" && key.Name == entry.KeyName
+		if !matchesID && !matchesName {
+			continue
+		}
+
+		if err := client.RevokeAdminAPIKey(ctx, key.ID); err != nil {
+			return err
+		}
+		b.Logger().Info("Revoked admin API key left by an incomplete rotation", "connection", entry.Connection)
+	}
+
+	return nil
 }
@@ -0,0 +1,169 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Schema versions of the stored entities. Entries written before schema
+// versioning have version 0. To change a stored entity, bump its version and
+// append a migration from the previous version; entries are upgraded when
+// read and persisted by migrateStorage when the mount is initialized.
+const (
+	configSchemaVersion = 1
+	roleSchemaVersion   = 1
+
+	// leaseSchemaVersion is recorded in the internal data of every lease.
+	// Leases are never rewritten, so dynamicCredsRevoke must keep accepting
+	// internal data of every earlier version.
+	leaseSchemaVersion = 1
+)
+
+// configMigrations[v] upgrades a connection config from schema version v to
+// v+1.
+var configMigrations = []func(*openaiConfig){
+	// 0 -> 1: configs written before api_endpoint had a default.
+	func(c *openaiConfig) {
+		if c.APIEndpoint == "" {
+			c.APIEndpoint = DefaultAPIEndpoint
+		}
+	},
+}
+
+// roleMigrations[v] upgrades a role from schema version v to v+1.
+var roleMigrations = []func(*dynamicRoleEntry){
+	// 0 -> 1: roles written before named connections and role defaults.
+	func(r *dynamicRoleEntry) {
+		if r.Connection == "" {
+			r.Connection = defaultConnectionName
+		}
+		if r.ServiceAccountNameTemplate == "" {
+			r.ServiceAccountNameTemplate = defaultServiceAccountNameTemplate
+		}
+		if r.ServiceAccountDescription == "" {
+			r.ServiceAccountDescription = defaultServiceAccountDescription
+		}
+		if r.TTL == 0 {
+			r.TTL = defaultRoleTTL
+		}
+		if r.MaxTTL == 0 {
+			r.MaxTTL = defaultRoleMaxTTL
+		}
+	},
+}
+
+// upgradeConfig migrates c to configSchemaVersion and reports whether it
+// changed. Entries from a newer plugin version are left untouched.
+func upgradeConfig(c *openaiConfig) bool {
+	upgraded := false
+	for c.SchemaVersion < configSchemaVersion {
+		configMigrations[c.SchemaVersion](c)
+		c.SchemaVersion++
+		upgraded = true
+	}
+	return upgraded
+}
+
+// upgradeRole migrates r to roleSchemaVersion and reports whether it changed.
+// Entries from a newer plugin version are left untouched.
+func upgradeRole(r *dynamicRoleEntry) bool {
+	upgraded := false
+	for r.SchemaVersion < roleSchemaVersion {
+		roleMigrations[r.SchemaVersion](r)
+		r.SchemaVersion++
+		upgraded = true
+	}
+	return upgraded
+}
+
+// migrateStorage persists the schema upgrades of every stored connection
+// config and role. Reads upgrade entries in memory anyway, so a failure is
+// returned for logging but leaves the mount usable.
+func (b *backend) migrateStorage(ctx context.Context, s logical.Storage) error {
+	if !b.canWriteReplicatedStorage() {
+		return nil
+	}
+
+	names, err := s.List(ctx, configPath+"/")
+	if err != nil {
+		return fmt.Errorf("error listing connections: %w", err)
+	}
+	names = append([]string{defaultConnectionName}, names...)
+
+	var errs error
+	for _, name := range names {
+		if err := b.migrateConnectionConfig(ctx, s, name); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("connection %q: %w", name, err))
+		}
+	}
+
+	roles, err := s.List(ctx, roleStoragePrefix)
+	if err != nil {
+		return errors.Join(errs, fmt.Errorf("error listing roles: %w", err))
+	}
+	for _, name := range roles {
+		if err := b.migrateRole(ctx, s, name); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("role %q: %w", name, err))
+		}
+	}
+	return errs
+}
+
+// migrateConnectionConfig persists the schema upgrade of a connection config.
+func (b *backend) migrateConnectionConfig(ctx context.Context, s logical.Storage, name string) error {
+	lock := b.configLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	config, upgraded, err := readConnectionConfig(ctx, s, name)
+	if err != nil || config == nil {
+		return err
+	}
+	if config.SchemaVersion > configSchemaVersion {
+		b.Logger().Warn("Connection config was written by a newer plugin version",
+			"connection", name, "schema_version", config.SchemaVersion, "supported", configSchemaVersion)
+		return nil
+	}
+	if !upgraded {
+		return nil
+	}
+
+	if err := putConnectionConfig(ctx, s, name, config); err != nil {
+		return err
+	}
+	b.Logger().Info("Migrated connection config", "connection", name, "schema_version", config.SchemaVersion)
+	return nil
+}
+
+// migrateRole persists the schema upgrade of a role.
+func (b *backend) migrateRole(ctx context.Context, s logical.Storage, name string) error {
+	lock := locksutil.LockForKey(b.roleLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	role, upgraded, err := readRole(ctx, s, name)
+	if err != nil || role == nil {
+		return err
+	}
+	if role.SchemaVersion > roleSchemaVersion {
+		b.Logger().Warn("Role was written by a newer plugin version",
+			"role", name, "schema_version", role.SchemaVersion, "supported", roleSchemaVersion)
+		return nil
+	}
+	if !upgraded {
+		return nil
+	}
+
+	if err := putRole(ctx, s, name, role); err != nil {
+		return err
+	}
+	b.Logger().Info("Migrated role", "role", name, "schema_version", role.SchemaVersion)
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// putRawEntry stores value as JSON at key, bypassing the typed helpers so
+// tests can write entries the way older plugin versions did.
+func putRawEntry(t *testing.T, s logical.Storage, key string, value interface{}) {
+	t.Helper()
+	entry, err := logical.StorageEntryJSON(key, value)
+	require.NoError(t, err)
+	require.NoError(t, s.Put(context.Background(), entry))
+}
+
+func TestUpgradeConfig(t *testing.T) {
+	config := &openaiConfig{AdminAPIKey: TestAPIKey}
+	assert.True(t, upgradeConfig(config))
+	assert.Equal(t, configSchemaVersion, config.SchemaVersion)
+	assert.Equal(t, DefaultAPIEndpoint, config.APIEndpoint)
+
+	// Current and newer entries are left as they are.
+	assert.False(t, upgradeConfig(config))
+	newer := &openaiConfig{SchemaVersion: configSchemaVersion + 1}
+	assert.False(t, upgradeConfig(newer))
+	assert.Empty(t, newer.APIEndpoint)
+}
+
+func TestUpgradeRole(t *testing.T) {
+	role := &dynamicRoleEntry{ProjectID: TestProjectID, TTL: 2 * time.Hour}
+	assert.True(t, upgradeRole(role))
+	assert.Equal(t, roleSchemaVersion, role.SchemaVersion)
+	assert.Equal(t, defaultConnectionName, role.Connection)
+	assert.Equal(t, defaultServiceAccountNameTemplate, role.ServiceAccountNameTemplate)
+	assert.Equal(t, defaultServiceAccountDescription, role.ServiceAccountDescription)
+	assert.Equal(t, 2*time.Hour, role.TTL, "set values must be kept")
+	assert.Equal(t, defaultRoleMaxTTL, role.MaxTTL)
+
+	assert.False(t, upgradeRole(role))
+}
+
+func TestMigrateStorage(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	// Entries as written before schema versioning.
+	putRawEntry(t, storage, configPath, map[string]interface{}{
+		"admin_api_key":   TestAPIKey,
+		"organization_id": TestOrganizationID,
+	})
+	putRawEntry(t, storage, connectionStoragePath("research"), map[string]interface{}{
+		"admin_api_key":   TestAPIKey,
+		"organization_id": TestOrganizationID,
+		"api_endpoint":    "https://egress.example.com/v1",
+	})
+	putRawEntry(t, storage, roleStoragePath("legacy"), map[string]interface{}{
+		"project_id": TestProjectID,
+	})
+
+	require.NoError(t, b.migrateStorage(ctx, storage))
+
+	config, upgraded, err := readConnectionConfig(ctx, storage, defaultConnectionName)
+	require.NoError(t, err)
+	assert.False(t, upgraded, "the migrated entry must be persisted")
+	assert.Equal(t, configSchemaVersion, config.SchemaVersion)
+	assert.Equal(t, DefaultAPIEndpoint, config.APIEndpoint)
+	assert.Equal(t, uint64(1), config.Version)
+
+	config, upgraded, err = readConnectionConfig(ctx, storage, "research")
+	require.NoError(t, err)
+	assert.False(t, upgraded)
+	assert.Equal(t, "https://egress.example.com/v1", config.APIEndpoint)
+
+	role, upgraded, err := readRole(ctx, storage, "legacy")
+	require.NoError(t, err)
+	assert.False(t, upgraded)
+	assert.Equal(t, roleSchemaVersion, role.SchemaVersion)
+	assert.Equal(t, defaultConnectionName, role.Connection)
+	assert.Equal(t, defaultRoleTTL, role.TTL)
+
+	// Migrating again changes nothing.
+	require.NoError(t, b.migrateStorage(ctx, storage))
+	config, err = getConfig(ctx, storage)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), config.Version)
+}
+
+func TestMigrateStorage_KeepsNewerEntries(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	putRawEntry(t, storage, roleStoragePath("future"), map[string]interface{}{
+		"project_id":     TestProjectID,
+		"schema_version": roleSchemaVersion + 1,
+	})
+
+	require.NoError(t, b.migrateStorage(ctx, storage))
+
+	entry, err := storage.Get(ctx, roleStoragePath("future"))
+	require.NoError(t, err)
+	var raw map[string]interface{}
+	require.NoError(t, entry.DecodeJSON(&raw))
+	assert.NotContains(t, raw, "connection", "entries from a newer plugin version must not be rewritten")
+}
+
+func TestDynamicCredsRevoke_LegacyLease(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	var deletedProject string
+	b.setClient(defaultConnectionName, &mockClient{
+		deleteServiceAccountFn: func(_ context.Context, _ string, projectID ...string) error {
+			deletedProject = projectID[0]
+			return nil
+		},
+	})
+	putRawEntry(t, storage, roleStoragePath("legacy"), map[string]interface{}{
+		"project_id": TestProjectID2,
+	})
+
+	// Early leases carry neither project_id nor the role; the role is taken
+	// from the path that issued the lease.
+	_, err := b.dynamicCredsRevoke(ctx, &logical.Request{
+		Storage: storage,
+		Path:    "creds/legacy",
+		Secret: &logical.Secret{InternalData: map[string]interface{}{
+			"api_key_id":         "key-123",
+			"service_account_id": "svc-123",
+		}},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, TestProjectID2, deletedProject)
+
+	// A lease whose role no longer exists cannot be resolved.
+	_, err = b.dynamicCredsRevoke(ctx, &logical.Request{
+		Storage: storage,
+		Path:    "creds/deleted",
+		Secret: &logical.Secret{InternalData: map[string]interface{}{
+			"api_key_id":         "key-123",
+			"service_account_id": "svc-123",
+		}},
+	}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not be found")
+}
+
+func TestCredsCreate_RecordsLeaseSchema(t *testing.T) {
+	b := getTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+
+	putRawEntry(t, storage, roleStoragePath("analytics"), map[string]interface{}{
+		"project_id": TestProjectID,
+	})
+
+	resp, err := b.pathCredsCreate(ctx, &logical.Request{Storage: storage}, &framework.FieldData{
+		Raw:    map[string]interface{}{"name": "analytics"},
+		Schema: b.pathDynamicCredsCreate()[0].Fields,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, "analytics", resp.Secret.InternalData["role"])
+	assert.Equal(t, leaseSchemaVersion, resp.Secret.InternalData["schema_version"])
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -42,6 +43,13 @@ type mockClient struct {
 	setConfigFn            func(config *Config) error
 	listServiceAccountsFn  func(ctx context.Context, projectID string) ([]*ServiceAccount, error)
 	getServiceAccountFn    func(ctx context.Context, serviceAccountID, projectID string) (*ServiceAccount, error)
+
+	createAdminAPIKeyFn     func(ctx context.Context, name string) (string, string, error)
+	revokeAdminAPIKeyFn     func(ctx context.Context, keyID string) error
+	listAdminAPIKeysFn      func(ctx context.Context) ([]*AdminAPIKey, error)
+	getAdminAPIKeyFn        func(ctx context.Context, keyID string) (*AdminAPIKey, error)
+	discoverAdminAPIKeyIDFn func(ctx context.Context) (string, error)
+	testConnectionFn        func(ctx context.Context) error
 }
 
 func (m *mockClient) CreateServiceAccount(ctx context.Context, projectID string, req CreateServiceAccountRequest) (*ServiceAccount, *APIKey, error) {
@@ -93,6 +101,48 @@ func (m *mockClient) ValidateProject(ctx context.Context, projectID string) erro
 	return nil
 }
 
+func (m *mockClient) CreateAdminAPIKey(ctx context.Context, name string) (string, string, error) {
+	if m.createAdminAPIKeyFn != nil {
+		return m.createAdminAPIKeyFn(ctx, name)
+	}
+	return "sk-admin-new", "key-new", nil
+}
+
+func (m *mockClient) RevokeAdminAPIKey(ctx context.Context, keyID string) error {
+	if m.revokeAdminAPIKeyFn != nil {
+		return m.revokeAdminAPIKeyFn(ctx, keyID)
+	}
+	return nil
+}
+
+func (m *mockClient) ListAdminAPIKeys(ctx context.Context) ([]*AdminAPIKey, error) {
+	if m.listAdminAPIKeysFn != nil {
+		return m.listAdminAPIKeysFn(ctx)
+	}
+	return []*AdminAPIKey{{ID: TestAdminAPIKeyID, Name: "sample-admin-key"}}, nil
+}
+
+func (m *mockClient) GetAdminAPIKey(ctx context.Context, keyID string) (*AdminAPIKey, error) {
+	if m.getAdminAPIKeyFn != nil {
+		return m.getAdminAPIKeyFn(ctx, keyID)
+	}
+	return &AdminAPIKey{ID: keyID, Name: "sample-admin-key"}, nil
+}
+
+func (m *mockClient) DiscoverAdminAPIKeyID(ctx context.Context) (string, error) {
+	if m.discoverAdminAPIKeyIDFn != nil {
+		return m.discoverAdminAPIKeyIDFn(ctx)
+	}
+	return TestAdminAPIKeyID, nil
+}
+
+func (m *mockClient) TestConnection(ctx context.Context) error {
+	if m.testConnectionFn != nil {
+		return m.testConnectionFn(ctx)
+	}
+	return nil
+}
+
 // getTestBackend returns a configured backend for testing.
 func getTestBackend(t *testing.T) *backend {
 	mockClient := &mockClient{}
@@ -134,6 +184,14 @@ func (d testSystemView) DeregisterRotationJob(_ context.Context, _ *rotation.Rot
 	return nil
 }
 
+// testNextRotation is the next rotation time reported by testSystemView.
+var testNextRotation = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func (d testSystemView) GetRotationInformation(_ context.Context, _ *rotation.RotationInfoRequest) (*rotation.RotationInfoResponse, error) {
+	// Mock implementation for tests - report a fixed schedule
+	return &rotation.RotationInfoResponse{NextVaultRotation: testNextRotation}, nil
+}
+
 // repeat generates a repeated string for testing purposes
 func repeat(s string, count int) string {
 	if count <= 0 {
@@ -0,0 +1,168 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	// tracerName is the instrumentation scope of the plugin's spans.
+	tracerName = "github.com/gitrgoliveira/vault-plugin-secrets-openai/plugin"
+
+	// tracingServiceName is the service.name of the plugin's spans.
+	tracingServiceName = "vault-plugin-secrets-openai"
+)
+
+// Span attributes set by the plugin.
+const (
+	attrRole       = attribute.Key("openai.vault.role")
+	attrConnection = attribute.Key("openai.vault.connection")
+	attrRotated    = attribute.Key("openai.admin_key.rotated")
+	attrAttempts   = attribute.Key("openai.request.attempts")
+
+	attrRequestID       = attribute.Key("openai.request_id")
+	attrClientRequestID = attribute.Key("openai.client_request_id")
+)
+
+// newTracerProviderFromEnv returns the tracer provider configured by the
+// standard OpenTelemetry environment variables, which Vault passes to the
+// plugin when it is registered with -env. Tracing is enabled by setting
+// OTEL_TRACES_EXPORTER=otlp or an OTLP endpoint; OTEL_EXPORTER_OTLP_PROTOCOL
+// selects grpc or http/protobuf, the default. The exporter reads its other
+// settings, such as headers and TLS, from the environment itself.
+//
+// It returns a no-op provider and a nil shutdown function when tracing is
+// not enabled or cannot be set up.
+func newTracerProviderFromEnv(ctx context.Context, getenv func(string) string) (trace.TracerProvider, func(context.Context) error, error) {
+	enabled, err := tracingEnabled(getenv)
+	if err != nil || !enabled {
+		return noop.NewTracerProvider(), nil, err
+	}
+
+	protocol := getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch protocol {
+	case "", "http/protobuf":
+		exporter, err = otlptracehttp.New(ctx)
+	case "grpc":
+		exporter, err = otlptracegrpc.New(ctx)
+	default:
+		return noop.NewTracerProvider(), nil, fmt.Errorf("unsupported OTLP protocol %q: must be grpc or http/protobuf", protocol)
+	}
+	if err != nil {
+		return noop.NewTracerProvider(), nil, fmt.Errorf("error creating OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(semconv.ServiceName(tracingServiceName)))
+	if err != nil {
+		return noop.NewTracerProvider(), nil, fmt.Errorf("error creating trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	return provider, provider.Shutdown, nil
+}
+
+// tracingEnabled reports whether the environment enables trace export.
+func tracingEnabled(getenv func(string) string) (bool, error) {
+	switch exporter := strings.TrimSpace(getenv("OTEL_TRACES_EXPORTER")); exporter {
+	case "otlp":
+		return true, nil
+	case "none":
+		return false, nil
+	case "":
+		return getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+			getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "", nil
+	default:
+		return false, fmt.Errorf("unsupported trace exporter %q: must be otlp or none", exporter)
+	}
+}
+
+// startSpan starts a span of a backend operation with the backend's tracer.
+func (b *backend) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return b.tracerProvider.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends a span of a backend operation, marking it failed when the
+// operation returned an error or an error response.
+func endSpan(span trace.Span, resp *logical.Response, err error) {
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp != nil && resp.IsError():
+		span.SetStatus(codes.Error, resp.Error().Error())
+	}
+	span.End()
+}
+
+// startRequestSpan starts the span of an OpenAI API call with the client's
+// tracer. Calls made by a backend operation are traced as children of its
+// span.
+func (c *Client) startRequestSpan(ctx context.Context, method, path, clientRequestID string) (context.Context, trace.Span) {
+	template := pathTemplate(path)
+	return c.tracer.Start(ctx, method+" "+template,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLTemplate(template),
+			attrClientRequestID.String(clientRequestID),
+		))
+}
+
+// endRequestSpan ends the span of an OpenAI API call with the outcome of its
+// last attempt. last is nil when no request was sent.
+func endRequestSpan(span trace.Span, last *endpointResponse, attempts int, err error) {
+	span.SetAttributes(attrAttempts.Int(attempts))
+	if last != nil && last.statusCode != 0 {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(last.statusCode))
+		if id := last.header.Get(requestIDHeader); id != "" {
+			span.SetAttributes(attrRequestID.String(id))
+		}
+	}
+	endSpan(span, nil, err)
+}
+
+// pathTemplates maps the collections of the OpenAI API paths the client uses
+// to the placeholder that replaces the ID following them.
+var pathTemplates = map[string]string{
+	"projects":         "{project_id}",
+	"service_accounts": "{service_account_id}",
+	"admin_api_keys":   "{key_id}",
+	"api_keys":         "{api_key_id}",
+}
+
+// pathTemplate returns path with its query removed and object IDs replaced
+// by placeholders, so spans of the same call can be grouped.
+func pathTemplate(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		if placeholder, ok := pathTemplates[segments[i-1]]; ok && segments[i] != "" {
+			segments[i] = placeholder
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,228 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceTestBackend returns a test backend whose spans are recorded by an
+// in-memory exporter.
+func traceTestBackend(t *testing.T) (*backend, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	b := getTestBackend(t)
+	b.setTracerProvider(provider)
+	return b, exporter
+}
+
+// spanNamed returns the first recorded span with the given name.
+func spanNamed(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not found", "no span named %q", name)
+	return tracetest.SpanStub{}
+}
+
+// spanAttribute returns the value of a span attribute.
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestPathTemplate(t *testing.T) {
+	assert.Equal(t, "/organization/admin_api_keys", pathTemplate("/organization/admin_api_keys?limit=100&after=key_1"))
+	assert.Equal(t, "/organization/admin_api_keys/{key_id}", pathTemplate("/organization/admin_api_keys/key_1"))
+	assert.Equal(t, "/organization/projects/{project_id}", pathTemplate("/organization/projects/proj_1"))
+	assert.Equal(t, "/organization/projects/{project_id}/service_accounts/{service_account_id}",
+		pathTemplate("/organization/projects/proj_1/service_accounts/svc_1"))
+}
+
+func TestTracingEnabled(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	enabled, err := tracingEnabled(env(nil))
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	enabled, err = tracingEnabled(env(map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318"}))
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = tracingEnabled(env(map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_TRACES_EXPORTER": "none"}))
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	enabled, err = tracingEnabled(env(map[string]string{"OTEL_TRACES_EXPORTER": "otlp"}))
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	_, err = tracingEnabled(env(map[string]string{"OTEL_TRACES_EXPORTER": "zipkin"}))
+	require.Error(t, err)
+
+	provider, shutdown, err := newTracerProviderFromEnv(context.Background(), env(map[string]string{
+		"OTEL_TRACES_EXPORTER":        "otlp",
+		"OTEL_EXPORTER_OTLP_PROTOCOL": "http/json",
+	}))
+	require.Error(t, err)
+	assert.Nil(t, shutdown)
+	assert.NotNil(t, provider, "a no-op provider is returned on error")
+}
+
+func TestTracing_CredsCreateAndRevoke(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b, exporter := traceTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+
+	resp, err := b.pathRoleWrite(ctx, &logical.Request{Storage: storage}, &framework.FieldData{
+		Raw:    map[string]interface{}{"name": "analytics", "project_id": TestProjectID},
+		Schema: b.pathDynamicSvcAccount()[0].Fields,
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	exporter.Reset()
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "creds/analytics",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	require.NotNil(t, resp.Secret)
+
+	spans := exporter.GetSpans()
+	create := spanNamed(t, spans, "openai.creds.create")
+	assert.Equal(t, "analytics", spanAttribute(create, attrRole).AsString())
+	assert.Equal(t, defaultConnectionName, spanAttribute(create, attrConnection).AsString())
+	assert.Equal(t, codes.Unset, create.Status.Code)
+
+	call := spanNamed(t, spans, "POST /organization/projects/{project_id}/service_accounts")
+	assert.Equal(t, trace.SpanKindClient, call.SpanKind)
+	assert.Equal(t, create.SpanContext.SpanID(), call.Parent.SpanID(), "client calls are children of the operation")
+	assert.Equal(t, create.SpanContext.TraceID(), call.SpanContext.TraceID())
+	assert.Equal(t, "POST", spanAttribute(call, "http.request.method").AsString())
+	assert.Equal(t, "/organization/projects/{project_id}/service_accounts", spanAttribute(call, "url.template").AsString())
+	assert.Equal(t, int64(200), spanAttribute(call, "http.response.status_code").AsInt64())
+	assert.Equal(t, int64(1), spanAttribute(call, attrAttempts).AsInt64())
+	assert.NotEmpty(t, spanAttribute(call, attrClientRequestID).AsString())
+
+	exporter.Reset()
+	_, err = b.dynamicCredsRevoke(ctx, &logical.Request{
+		Storage: storage,
+		Secret:  &logical.Secret{InternalData: resp.Secret.InternalData},
+	}, nil)
+	require.NoError(t, err)
+
+	spans = exporter.GetSpans()
+	revoke := spanNamed(t, spans, "openai.creds.revoke")
+	assert.Equal(t, defaultConnectionName, spanAttribute(revoke, attrConnection).AsString())
+	call = spanNamed(t, spans, "DELETE /organization/projects/{project_id}/service_accounts/{service_account_id}")
+	assert.Equal(t, revoke.SpanContext.SpanID(), call.Parent.SpanID())
+}
+
+func TestTracing_FailedOperation(t *testing.T) {
+	b, exporter := traceTestBackend(t)
+	storage := &logical.InmemStorage{}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "creds/missing",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	create := spanNamed(t, exporter.GetSpans(), "openai.creds.create")
+	assert.Equal(t, codes.Error, create.Status.Code)
+	assert.Contains(t, create.Status.Description, "does not exist")
+}
+
+func TestTracing_RotateAdminAPIKey(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b, exporter := traceTestBackend(t)
+	storage := &logical.InmemStorage{}
+	ctx := context.Background()
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+	exporter.Reset()
+
+	rotated, err := b.rotateAdminAPIKey(ctx, storage, defaultConnectionName)
+	require.NoError(t, err)
+	require.NotNil(t, rotated)
+
+	spans := exporter.GetSpans()
+	rotate := spanNamed(t, spans, "openai.admin_key.rotate")
+	assert.True(t, spanAttribute(rotate, attrRotated).AsBool())
+	assert.Equal(t, defaultConnectionName, spanAttribute(rotate, attrConnection).AsString())
+
+	call := spanNamed(t, spans, "POST /organization/admin_api_keys")
+	assert.Equal(t, rotate.SpanContext.SpanID(), call.Parent.SpanID())
+}
+
+func TestTracing_FailedClientCall(t *testing.T) {
+	var hits int32
+	server := endpointTestServer(t, 503, &hits)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	client := paginationTestClient(t, server.URL)
+	client.tracer = provider.Tracer(tracerName)
+
+	_, err := client.ListAdminAPIKeys(context.Background())
+	require.Error(t, err)
+
+	call := spanNamed(t, exporter.GetSpans(), "GET /organization/admin_api_keys")
+	assert.Equal(t, codes.Error, call.Status.Code)
+	assert.Equal(t, int64(503), spanAttribute(call, "http.response.status_code").AsInt64())
+	assert.False(t, call.Parent.IsValid(), "calls outside a backend operation are root spans")
+}
+
+func TestTracing_HealthCheckCalls(t *testing.T) {
+	mockServer := NewMockOpenAIServer()
+	defer mockServer.Close()
+
+	b, exporter := traceTestBackend(t)
+	storage := &logical.InmemStorage{}
+	writeMockServerConfig(t, b, storage, mockServer, nil)
+	exporter.Reset()
+
+	// The health check has no span of its own; its API calls are traced
+	// with the tracer provider the backend gave its clients.
+	resp := readHealth(t, b, storage, "config/health")
+	require.NotNil(t, resp)
+	assert.Equal(t, healthStatusHealthy, resp.Data["status"])
+
+	call := spanNamed(t, exporter.GetSpans(), "GET /organization/admin_api_keys/{key_id}")
+	assert.Equal(t, trace.SpanKindClient, call.SpanKind)
+	assert.Equal(t, int64(200), spanAttribute(call, "http.response.status_code").AsInt64())
+}
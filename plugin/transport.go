@@ -0,0 +1,276 @@
+// Copyright Ricardo Oliveira 2025.
+// SPDX-License-Identifier: MPL-2.0
+
+package openaisecrets
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRequestTimeout bounds a single HTTP request to the OpenAI API,
+	// including reading the response body.
+	DefaultRequestTimeout = 30 * time.Second
+
+	// defaultKeepAlive is the TCP keep-alive period for outbound connections.
+	defaultKeepAlive = 30 * time.Second
+
+	// defaultDialTimeout bounds connection setup when connect_timeout is not
+	// configured.
+	defaultDialTimeout = 30 * time.Second
+
+	// Idle connections kept open for reuse. All requests of a connection go
+	// to a handful of hosts, so most idle connections are per host; see
+	// maxIdleConnsPerHost.
+	defaultMaxIdleConns    = 100
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
+// maxIdleConnsPerHost returns the idle connections a transport keeps per
+// host: one for each request the connection allows in flight, so bursts up
+// to max_in_flight do not reconnect.
+func maxIdleConnsPerHost(config *Config) int {
+	if config.MaxInFlight > 0 {
+		return config.MaxInFlight
+	}
+	return DefaultMaxInFlight
+}
+
+// validateProxyURL performs a fail-fast syntax check for proxy_url at
+// config-write time.
+func validateProxyURL(rawURL string) (*url.URL, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("proxy_url is not a valid URL: %w", err)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy_url must use http, https or socks5 (got %q)", parsed.Scheme)
+	}
+	if parsed.Hostname() == "" {
+		return nil, fmt.Errorf("proxy_url must include a host")
+	}
+	return parsed, nil
+}
+
+// newTLSConfig builds the TLS configuration for outbound connections. A custom
+// CA bundle is added to the system roots so that TLS-intercepting egress
+// proxies can be trusted without losing the public CAs. A client certificate
+// and key enable mutual TLS.
+func newTLSConfig(config *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Resume TLS sessions instead of performing a full handshake on
+		// every new connection.
+		ClientSessionCache: tls.NewLRUClientSessionCache(0),
+	}
+
+	if config.CACertificate != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(config.CACertificate)) {
+			return nil, fmt.Errorf("ca_certificate does not contain a valid PEM certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case config.ClientCertificate != "" && config.ClientKey != "":
+		cert, err := tls.X509KeyPair([]byte(config.ClientCertificate), []byte(config.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client_certificate or client_key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case config.ClientCertificate != "" || config.ClientKey != "":
+		return nil, fmt.Errorf("client_certificate and client_key must be set together")
+	}
+
+	return tlsConfig, nil
+}
+
+// newTransport builds the HTTP transport used to reach the OpenAI API from
+// the egress settings in config: custom CA bundle, client certificate for
+// mTLS, proxy URL and connect timeout. Without a proxy URL the standard
+// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables apply. Connections
+// are kept alive for reuse, and HTTP/2 is used when the server supports it.
+func newTransport(config *Config) (*http.Transport, error) {
+	if config.ConnectTimeout < 0 {
+		return nil, fmt.Errorf("connect_timeout must not be negative")
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if config.ProxyURL != "" {
+		proxyURL, err := validateProxyURL(config.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	dialTimeout := defaultDialTimeout
+	handshakeTimeout := 10 * time.Second
+	if config.ConnectTimeout > 0 {
+		dialTimeout = config.ConnectTimeout
+		handshakeTimeout = config.ConnectTimeout
+	}
+
+	idlePerHost := maxIdleConnsPerHost(config)
+
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: defaultKeepAlive,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   handshakeTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          max(defaultMaxIdleConns, idlePerHost),
+		MaxIdleConnsPerHost:   idlePerHost,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		ExpectContinueTimeout: time.Second,
+	}, nil
+}
+
+// newHTTPClient builds the HTTP client used to reach the OpenAI API. Its
+// transport is taken from transports, or built for the client alone when
+// transports is nil; see newTransport.
+func newHTTPClient(config *Config, transports *transportPool) (*http.Client, error) {
+	if config.RequestTimeout < 0 {
+		return nil, fmt.Errorf("request_timeout must not be negative")
+	}
+
+	var transport *http.Transport
+	var err error
+	if transports != nil {
+		transport, err = transports.get(config)
+	} else {
+		transport, err = newTransport(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	requestTimeout := config.RequestTimeout
+	if requestTimeout == 0 {
+		requestTimeout = DefaultRequestTimeout
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   requestTimeout,
+	}, nil
+}
+
+// transportPool keeps one HTTP transport for each connection of a mount.
+// Clients are rebuilt on every config write and admin key rotation; taking
+// their transport from the pool keeps open connections and TLS sessions
+// across those rebuilds, whatever their credentials.
+//
+// When a connection's egress settings or in-flight limit change, its
+// transport is replaced and the idle connections of the old one are closed,
+// so the pool never holds more transports than there are connections. The
+// old transport, and the client key it holds, is released once the clients
+// still using it are.
+type transportPool struct {
+	mu         sync.Mutex
+	transports map[string]*pooledTransport
+}
+
+// pooledTransport is a connection's transport and the settings it was built
+// from.
+type pooledTransport struct {
+	key       string
+	transport *http.Transport
+}
+
+// newTransportPool returns an empty transport pool.
+func newTransportPool() *transportPool {
+	return &transportPool{transports: make(map[string]*pooledTransport)}
+}
+
+// get returns the transport of the connection named in config, building it
+// on first use or when the connection's settings have changed. Clients that
+// belong to no connection get a transport of their own.
+func (p *transportPool) get(config *Config) (*http.Transport, error) {
+	if config.Connection == "" {
+		return newTransport(config)
+	}
+	key := transportKey(config)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current, ok := p.transports[config.Connection]
+	if ok && current.key == key {
+		return current.transport, nil
+	}
+	transport, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		current.transport.CloseIdleConnections()
+	}
+	p.transports[config.Connection] = &pooledTransport{key: key, transport: transport}
+	return transport, nil
+}
+
+// remove closes the idle connections of the named connection's transport and
+// drops it from the pool, for when the connection is deleted.
+func (p *transportPool) remove(connection string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if current, ok := p.transports[connection]; ok {
+		current.transport.CloseIdleConnections()
+		delete(p.transports, connection)
+	}
+}
+
+// close closes the idle connections of every transport and empties the
+// pool. Requests still in flight complete normally.
+func (p *transportPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for connection, current := range p.transports {
+		current.transport.CloseIdleConnections()
+		delete(p.transports, connection)
+	}
+}
+
+// transportKey identifies the egress settings and idle connection limit a
+// transport is built from. It is a hash, so the client key is not kept in the
+// pool alongside the transport.
+func transportKey(config *Config) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%q %q %q %q %d %d",
+		config.CACertificate, config.ClientCertificate, config.ClientKey,
+		config.ProxyURL, config.ConnectTimeout, maxIdleConnsPerHost(config))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// redactProxyURL hides any password embedded in a proxy URL so it can be
+// returned from config reads.
+func redactProxyURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Redacted()
+}